@@ -0,0 +1,316 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+const DefaultAdderShards = 16
+
+// adder 分片计数器的公共部分,每个分片是一个独立的key,集群模式下分布在不同的slot上
+type adder struct {
+	r      *Redis
+	name   string
+	shards int
+	next   uint32
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// LongAdder 本地缓冲增量并定期刷新到分片key上的int64计数器,用于热点计数
+type LongAdder struct {
+	local int64
+	adder
+}
+
+// DoubleAdder 本地缓冲增量并定期刷新到分片key上的float64计数器,用于热点计数
+type DoubleAdder struct {
+	mu    sync.Mutex
+	local float64
+	adder
+}
+
+/**
+ * 获取LongAdder
+ *
+ * param: string        name
+ * param: int           shards        分片数,小于等于0时使用 DefaultAdderShards
+ * param: time.Duration flushInterval 本地增量刷新间隔,小于等于0时不自动刷新,需要手动调用Flush
+ * return: *LongAdder
+ */
+func (r *Redis) GetLongAdder(name string, shards int, flushInterval time.Duration) *LongAdder {
+	a := &LongAdder{adder: newAdder(r, name, shards)}
+	a.start(flushInterval, a.Flush)
+	return a
+}
+
+/**
+ * 获取DoubleAdder
+ *
+ * param: string        name
+ * param: int           shards        分片数,小于等于0时使用 DefaultAdderShards
+ * param: time.Duration flushInterval 本地增量刷新间隔,小于等于0时不自动刷新,需要手动调用Flush
+ * return: *DoubleAdder
+ */
+func (r *Redis) GetDoubleAdder(name string, shards int, flushInterval time.Duration) *DoubleAdder {
+	a := &DoubleAdder{adder: newAdder(r, name, shards)}
+	a.start(flushInterval, a.Flush)
+	return a
+}
+
+func newAdder(r *Redis, name string, shards int) adder {
+	if shards <= 0 {
+		shards = DefaultAdderShards
+	}
+	var seed uint32
+	for _, c := range r.ID() {
+		seed = seed*31 + uint32(c)
+	}
+	return adder{r: r, name: r.namespaced(name), shards: shards, next: seed}
+}
+
+/**
+ * 增加delta,只记录在本地,等待刷新
+ *
+ * param: int64 delta
+ */
+func (a *LongAdder) Add(delta int64) {
+	atomic.AddInt64(&a.local, delta)
+}
+
+/**
+ * 加1
+ */
+func (a *LongAdder) Increment() {
+	a.Add(1)
+}
+
+/**
+ * 减1
+ */
+func (a *LongAdder) Decrement() {
+	a.Add(-1)
+}
+
+/**
+ * 将本地增量刷新到其中一个分片,失败时增量保留在本地
+ *
+ * return: error
+ */
+func (a *LongAdder) Flush(ctx context.Context) error {
+	delta := atomic.SwapInt64(&a.local, 0)
+	if delta == 0 {
+		return nil
+	}
+	if err := a.r.IncrBy(ctx, a.nextShard(), delta).Err(); err != nil {
+		atomic.AddInt64(&a.local, delta)
+		return err
+	}
+	return nil
+}
+
+/**
+ * 刷新本地增量后汇总所有分片
+ *
+ * return: int64
+ * return: error
+ */
+func (a *LongAdder) Sum(ctx context.Context) (int64, error) {
+	if err := a.Flush(ctx); err != nil {
+		return 0, err
+	}
+	values, err := a.values(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var sum int64
+	for _, v := range values {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		sum += n
+	}
+	return sum, nil
+}
+
+/**
+ * 清空本地增量和所有分片
+ *
+ * return: error
+ */
+func (a *LongAdder) Reset(ctx context.Context) error {
+	atomic.StoreInt64(&a.local, 0)
+	return a.reset(ctx)
+}
+
+/**
+ * 停止自动刷新并刷新剩余的本地增量
+ *
+ * return: error
+ */
+func (a *LongAdder) Close(ctx context.Context) error {
+	a.close()
+	return a.Flush(ctx)
+}
+
+/**
+ * 增加delta,只记录在本地,等待刷新
+ *
+ * param: float64 delta
+ */
+func (a *DoubleAdder) Add(delta float64) {
+	a.mu.Lock()
+	a.local += delta
+	a.mu.Unlock()
+}
+
+/**
+ * 将本地增量刷新到其中一个分片,失败时增量保留在本地
+ *
+ * return: error
+ */
+func (a *DoubleAdder) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	delta := a.local
+	a.local = 0
+	a.mu.Unlock()
+	if delta == 0 {
+		return nil
+	}
+	if err := a.r.IncrByFloat(ctx, a.nextShard(), delta).Err(); err != nil {
+		a.Add(delta)
+		return err
+	}
+	return nil
+}
+
+/**
+ * 刷新本地增量后汇总所有分片
+ *
+ * return: float64
+ * return: error
+ */
+func (a *DoubleAdder) Sum(ctx context.Context) (float64, error) {
+	if err := a.Flush(ctx); err != nil {
+		return 0, err
+	}
+	values, err := a.values(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, v := range values {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, err
+		}
+		sum += n
+	}
+	return sum, nil
+}
+
+/**
+ * 清空本地增量和所有分片
+ *
+ * return: error
+ */
+func (a *DoubleAdder) Reset(ctx context.Context) error {
+	a.mu.Lock()
+	a.local = 0
+	a.mu.Unlock()
+	return a.reset(ctx)
+}
+
+/**
+ * 停止自动刷新并刷新剩余的本地增量
+ *
+ * return: error
+ */
+func (a *DoubleAdder) Close(ctx context.Context) error {
+	a.close()
+	return a.Flush(ctx)
+}
+
+func (a *adder) shardKey(i int) string {
+	return a.name + ":" + strconv.Itoa(i)
+}
+
+func (a *adder) nextShard() string {
+	return a.shardKey(int(atomic.AddUint32(&a.next, 1) % uint32(a.shards)))
+}
+
+func (a *adder) start(interval time.Duration, flush func(ctx context.Context) error) {
+	if interval <= 0 {
+		return
+	}
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.stop:
+				return
+			case <-ticker.C:
+				_ = flush(context.Background())
+			}
+		}
+	}()
+}
+
+func (a *adder) close() {
+	a.once.Do(func() {
+		if a.stop != nil {
+			close(a.stop)
+			<-a.done
+		}
+	})
+}
+
+/**
+ * 读取所有存在的分片的值,各分片可能位于不同节点,使用pipeline读取
+ *
+ * return: []string
+ * return: error
+ */
+func (a *adder) values(ctx context.Context) ([]string, error) {
+	cmds := make([]*gredis.StringCmd, a.shards)
+	// pipeline只返回第一个错误,不存在的分片返回Nil,需要逐个检查其他命令的错误
+	_, _ = a.r.Pipelined(ctx, func(pipe gredis.Pipeliner) error {
+		for i := range cmds {
+			cmds[i] = pipe.Get(ctx, a.shardKey(i))
+		}
+		return nil
+	})
+	values := make([]string, 0, a.shards)
+	for _, cmd := range cmds {
+		v, err := cmd.Result()
+		if err == gredis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (a *adder) reset(ctx context.Context) error {
+	_, err := a.r.Pipelined(ctx, func(pipe gredis.Pipeliner) error {
+		for i := 0; i < a.shards; i++ {
+			pipe.Del(ctx, a.shardKey(i))
+		}
+		return nil
+	})
+	return err
+}
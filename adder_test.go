@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+// 连接不上的客户端,用于测试出错时的处理
+func unreachableRedis() *Redis {
+	return &Redis{UniversalClient: gredis.NewClient(&gredis.Options{
+		Addr:        "127.0.0.1:1",
+		MaxRetries:  -1,
+		DialTimeout: 100 * time.Millisecond,
+	})}
+}
+
+func TestAdder_nextShard(t *testing.T) {
+	a := newAdder(&Redis{}, "adder", 4)
+	counts := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		counts[a.nextShard()]++
+	}
+	for i := 0; i < 4; i++ {
+		if got := counts[a.shardKey(i)]; got != 2 {
+			t.Errorf("shard %d used %d times, want 2", i, got)
+		}
+	}
+}
+
+func TestAdder_values(t *testing.T) {
+	a := newAdder(unreachableRedis(), "adder", 4)
+	if _, err := a.values(context.Background()); err == nil {
+		t.Errorf("values() error = nil, want connection error")
+	}
+}
+
+func TestLongAdder_Flush(t *testing.T) {
+	a := &LongAdder{adder: newAdder(unreachableRedis(), "adder", 4)}
+	a.Add(5)
+	a.Increment()
+	if err := a.Flush(context.Background()); err == nil {
+		t.Fatalf("Flush() error = nil, want connection error")
+	}
+	if a.local != 6 {
+		t.Errorf("local = %d after failed Flush, want 6", a.local)
+	}
+}
@@ -0,0 +1,282 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+const (
+	// 不存在的key按0处理,按字符串比较,避免超过2^53的int64转为lua浮点数后精度丢失
+	AtomicCompareAndSetScript = "local v = redis.call('get', KEYS[1]) or '0' if v == ARGV[1] then redis.call('set', KEYS[1], ARGV[2]) return 1 end return 0"
+	// 不存在的key按0处理,按数值比较,使1和1.0这类写法不同的浮点数相等
+	AtomicDoubleCompareAndSetScript = "local v = redis.call('get', KEYS[1]) if (v == false and tonumber(ARGV[1]) == 0) or (v ~= false and tonumber(v) == tonumber(ARGV[1])) then redis.call('set', KEYS[1], ARGV[2]) return 1 end return 0"
+	AtomicGetAndDeleteScript        = "local v = redis.call('get', KEYS[1]) redis.call('del', KEYS[1]) if v == false then return '0' end return v"
+)
+
+var (
+	atomicCompareAndSetScripter       = gredis.NewScript(AtomicCompareAndSetScript)
+	atomicDoubleCompareAndSetScripter = gredis.NewScript(AtomicDoubleCompareAndSetScript)
+	atomicGetAndDeleteScripter        = gredis.NewScript(AtomicGetAndDeleteScript)
+)
+
+// AtomicLong 分布式int64计数器
+type AtomicLong struct {
+	r    *Redis
+	name string
+}
+
+// AtomicDouble 分布式float64计数器
+type AtomicDouble struct {
+	r    *Redis
+	name string
+}
+
+/**
+ * 获取AtomicLong
+ *
+ * param: string name
+ * return: *AtomicLong
+ */
+func (r *Redis) GetAtomicLong(name string) *AtomicLong {
+	return &AtomicLong{r: r, name: r.namespaced(name)}
+}
+
+/**
+ * 获取AtomicDouble
+ *
+ * param: string name
+ * return: *AtomicDouble
+ */
+func (r *Redis) GetAtomicDouble(name string) *AtomicDouble {
+	return &AtomicDouble{r: r, name: r.namespaced(name)}
+}
+
+/**
+ * 获取当前值,不存在时返回0
+ *
+ * return: int64
+ * return: error
+ */
+func (a *AtomicLong) Get(ctx context.Context) (int64, error) {
+	v, err := a.r.Get(ctx, a.name).Int64()
+	if err == gredis.Nil {
+		return 0, nil
+	}
+	return v, err
+}
+
+/**
+ * 设置值
+ *
+ * param: int64 value
+ * return: error
+ */
+func (a *AtomicLong) Set(ctx context.Context, value int64) error {
+	return a.r.Set(ctx, a.name, value, 0).Err()
+}
+
+/**
+ * 加1并返回新值
+ *
+ * return: int64
+ * return: error
+ */
+func (a *AtomicLong) IncrementAndGet(ctx context.Context) (int64, error) {
+	return a.AddAndGet(ctx, 1)
+}
+
+/**
+ * 减1并返回新值
+ *
+ * return: int64
+ * return: error
+ */
+func (a *AtomicLong) DecrementAndGet(ctx context.Context) (int64, error) {
+	return a.AddAndGet(ctx, -1)
+}
+
+/**
+ * 加delta并返回新值
+ *
+ * param: int64 delta
+ * return: int64
+ * return: error
+ */
+func (a *AtomicLong) AddAndGet(ctx context.Context, delta int64) (int64, error) {
+	return a.r.IncrBy(ctx, a.name, delta).Result()
+}
+
+/**
+ * 加delta并返回旧值
+ *
+ * param: int64 delta
+ * return: int64
+ * return: error
+ */
+func (a *AtomicLong) GetAndAdd(ctx context.Context, delta int64) (int64, error) {
+	v, err := a.AddAndGet(ctx, delta)
+	if err != nil {
+		return 0, err
+	}
+	return v - delta, nil
+}
+
+/**
+ * 设置新值并返回旧值
+ *
+ * param: int64 value
+ * return: int64
+ * return: error
+ */
+func (a *AtomicLong) GetAndSet(ctx context.Context, value int64) (int64, error) {
+	v, err := a.r.GetSet(ctx, a.name, value).Int64()
+	if err == gredis.Nil {
+		return 0, nil
+	}
+	return v, err
+}
+
+/**
+ * 当前值等于expect时设置为update
+ *
+ * param: int64 expect
+ * param: int64 update
+ * return: bool
+ * return: error
+ */
+func (a *AtomicLong) CompareAndSet(ctx context.Context, expect, update int64) (bool, error) {
+	res, err := atomicCompareAndSetScripter.Run(ctx, a.r, []string{a.name}, expect, update).Int()
+	return res == 1, err
+}
+
+/**
+ * 删除并返回删除前的值
+ *
+ * return: int64
+ * return: error
+ */
+func (a *AtomicLong) GetAndDelete(ctx context.Context) (int64, error) {
+	v, err := atomicGetAndDeleteScripter.Run(ctx, a.r, []string{a.name}).Text()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+/**
+ * 获取当前值,不存在时返回0
+ *
+ * return: float64
+ * return: error
+ */
+func (a *AtomicDouble) Get(ctx context.Context) (float64, error) {
+	v, err := a.r.Get(ctx, a.name).Float64()
+	if err == gredis.Nil {
+		return 0, nil
+	}
+	return v, err
+}
+
+/**
+ * 设置值
+ *
+ * param: float64 value
+ * return: error
+ */
+func (a *AtomicDouble) Set(ctx context.Context, value float64) error {
+	return a.r.Set(ctx, a.name, formatFloat(value), 0).Err()
+}
+
+/**
+ * 加1并返回新值
+ *
+ * return: float64
+ * return: error
+ */
+func (a *AtomicDouble) IncrementAndGet(ctx context.Context) (float64, error) {
+	return a.AddAndGet(ctx, 1)
+}
+
+/**
+ * 减1并返回新值
+ *
+ * return: float64
+ * return: error
+ */
+func (a *AtomicDouble) DecrementAndGet(ctx context.Context) (float64, error) {
+	return a.AddAndGet(ctx, -1)
+}
+
+/**
+ * 加delta并返回新值
+ *
+ * param: float64 delta
+ * return: float64
+ * return: error
+ */
+func (a *AtomicDouble) AddAndGet(ctx context.Context, delta float64) (float64, error) {
+	return a.r.IncrByFloat(ctx, a.name, delta).Result()
+}
+
+/**
+ * 加delta并返回旧值
+ *
+ * param: float64 delta
+ * return: float64
+ * return: error
+ */
+func (a *AtomicDouble) GetAndAdd(ctx context.Context, delta float64) (float64, error) {
+	v, err := a.AddAndGet(ctx, delta)
+	if err != nil {
+		return 0, err
+	}
+	return v - delta, nil
+}
+
+/**
+ * 设置新值并返回旧值
+ *
+ * param: float64 value
+ * return: float64
+ * return: error
+ */
+func (a *AtomicDouble) GetAndSet(ctx context.Context, value float64) (float64, error) {
+	v, err := a.r.GetSet(ctx, a.name, formatFloat(value)).Float64()
+	if err == gredis.Nil {
+		return 0, nil
+	}
+	return v, err
+}
+
+/**
+ * 当前值等于expect时设置为update
+ *
+ * param: float64 expect
+ * param: float64 update
+ * return: bool
+ * return: error
+ */
+func (a *AtomicDouble) CompareAndSet(ctx context.Context, expect, update float64) (bool, error) {
+	res, err := atomicDoubleCompareAndSetScripter.Run(ctx, a.r, []string{a.name}, formatFloat(expect), formatFloat(update)).Int()
+	return res == 1, err
+}
+
+/**
+ * 删除并返回删除前的值
+ *
+ * return: float64
+ * return: error
+ */
+func (a *AtomicDouble) GetAndDelete(ctx context.Context) (float64, error) {
+	v, err := atomicGetAndDeleteScripter.Run(ctx, a.r, []string{a.name}).Text()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
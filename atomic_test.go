@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{-2.5, "-2.5"},
+		{0.1, "0.1"},
+		{1e21, "1000000000000000000000"},
+		{1e-7, "0.0000001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatFloat(tt.v); got != tt.want {
+				t.Errorf("formatFloat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAtomicLong_CompareAndSet(t *testing.T) {
+	ctx := context.Background()
+	a := testRedis(t).GetAtomicLong("cas")
+	if _, err := a.GetAndDelete(ctx); err != nil {
+		t.Fatalf("GetAndDelete() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		expect int64
+		update int64
+		want   bool
+		value  int64
+	}{
+		{"missing key is zero", 0, 1 << 53, true, 1 << 53},
+		{"differs above 2^53", 1<<53 + 1, 1, false, 1 << 53},
+		{"equal above 2^53", 1 << 53, 1<<53 + 1, true, 1<<53 + 1},
+		{"neighbour above 2^53", 1 << 53, 2, false, 1<<53 + 1},
+		{"negative", 1<<53 + 1, -5, true, -5},
+		{"zero is not missing", 0, 7, false, -5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.CompareAndSet(ctx, tt.expect, tt.update)
+			if err != nil {
+				t.Fatalf("CompareAndSet() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompareAndSet() = %v, want %v", got, tt.want)
+			}
+			if v, err := a.Get(ctx); err != nil || v != tt.value {
+				t.Errorf("Get() = %v, %v, want %v", v, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestAtomicDouble_CompareAndSet(t *testing.T) {
+	ctx := context.Background()
+	a := testRedis(t).GetAtomicDouble("cas")
+	if _, err := a.GetAndDelete(ctx); err != nil {
+		t.Fatalf("GetAndDelete() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		expect float64
+		update float64
+		want   bool
+		value  float64
+	}{
+		{"missing key is zero", 0, 1.5, true, 1.5},
+		{"differs", 2.5, 3, false, 1.5},
+		{"equal", 1.5, 3, true, 3},
+		{"integer value", 3, 0.1, true, 0.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.CompareAndSet(ctx, tt.expect, tt.update)
+			if err != nil {
+				t.Fatalf("CompareAndSet() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompareAndSet() = %v, want %v", got, tt.want)
+			}
+			if v, err := a.Get(ctx); err != nil || v != tt.value {
+				t.Errorf("Get() = %v, %v, want %v", v, err, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	// 每次阻塞读取的最长时间,超时后检查ctx是否结束
+	blockingPollSlice = time.Second
+
+	// 剩余容量作为信号量保存在关联的key中,添加元素时获取一个许可,取出元素时在同一个脚本中归还,客户端异常不会泄漏许可
+	// 获取或归还许可后向对应的通知列表写入一个标记,唤醒等待的Take或Put,通知列表的长度不超过可以成功的等待者数量
+	// KEYS[1] 队列 KEYS[2] 剩余容量 KEYS[3] 等待空间的通知列表 KEYS[4] 等待元素的通知列表
+	boundedQueueSignalFunc = `
+local function signal(key, n)
+	redis.call('rpush', key, 1)
+	redis.call('ltrim', key, -math.max(n, 1), -1)
+end
+`
+	// ARGV[1] 元素
+	BoundedQueueOfferScript = boundedQueueSignalFunc + `
+local permits = redis.call('get', KEYS[2])
+if permits == false then
+	return -1
+end
+if tonumber(permits) <= 0 then
+	return 0
+end
+redis.call('decr', KEYS[2])
+signal(KEYS[4], redis.call('rpush', KEYS[1], ARGV[1]))
+return 1
+`
+	BoundedQueuePollScript = boundedQueueSignalFunc + `
+local v = redis.call('lpop', KEYS[1])
+if v ~= false then
+	signal(KEYS[3], redis.call('incr', KEYS[2]))
+end
+return v
+`
+	// ARGV[1] 容量,已经有元素时剩余容量需要减去队列长度
+	BoundedQueueTrySetCapacityScript = `
+if redis.call('exists', KEYS[2]) == 1 then
+	return 0
+end
+redis.call('set', KEYS[2], tonumber(ARGV[1]) - redis.call('llen', KEYS[1]))
+return 1
+`
+)
+
+var (
+	boundedQueueOfferScripter          = gredis.NewScript(BoundedQueueOfferScript)
+	boundedQueuePollScripter           = gredis.NewScript(BoundedQueuePollScript)
+	boundedQueueTrySetCapacityScripter = gredis.NewScript(BoundedQueueTrySetCapacityScript)
+)
+
+// BlockingQueue 支持阻塞读取的队列,阻塞命令使用独立的连接池
+type BlockingQueue[V any] struct {
+	Queue[V]
+}
+
+// BoundedBlockingQueue 有容量限制的阻塞队列,剩余容量作为信号量保存在关联的key中,队列已满时Put会阻塞
+// 元素的添加和取出都通过脚本完成,阻塞时只等待通知列表,没有收到通知的Take和Put每隔 blockingPollSlice 重试一次
+// 只能通过BoundedBlockingQueue的方法修改队列,否则剩余容量会不准确
+type BoundedBlockingQueue[V any] struct {
+	object
+	queue *BlockingQueue[V]
+}
+
+/**
+ * 获取BlockingQueue
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *BlockingQueue[V]
+ */
+func GetBlockingQueue[V any](r *Redis, name string) *BlockingQueue[V] {
+	return GetBlockingQueueWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的BlockingQueue
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *BlockingQueue[V]
+ */
+func GetBlockingQueueWithCodec[V any](r *Redis, name string, c codec.Codec) *BlockingQueue[V] {
+	return &BlockingQueue[V]{Queue: *GetQueueWithCodec[V](r, name, c)}
+}
+
+/**
+ * 在队尾添加元素
+ *
+ * param: V value
+ * return: error
+ */
+func (q *BlockingQueue[V]) Put(ctx context.Context, value V) error {
+	_, err := q.Add(ctx, value)
+	return err
+}
+
+/**
+ * 取出队头元素,队列为空时阻塞直到有元素或ctx结束
+ *
+ * return: V
+ * return: error ctx结束时返回ctx.Err()
+ */
+func (q *BlockingQueue[V]) Take(ctx context.Context) (V, error) {
+	v, _, _, err := q.poll(ctx, -1, nil)
+	return v, err
+}
+
+/**
+ * 取出队头元素,队列为空时最多等待timeout
+ *
+ * param: time.Duration timeout 小于等于0时不等待,精度为1秒
+ * return: V
+ * return: bool 超时返回false
+ * return: error
+ */
+func (q *BlockingQueue[V]) Poll(ctx context.Context, timeout time.Duration) (V, bool, error) {
+	if timeout <= 0 {
+		return q.Queue.Poll(ctx)
+	}
+	v, _, ok, err := q.poll(ctx, timeout, nil)
+	return v, ok, err
+}
+
+/**
+ * 从当前队列和names中第一个非空的队列取出队头元素,集群模式下所有队列需要位于同一个slot
+ *
+ * param: time.Duration timeout 小于等于0时一直等待直到ctx结束
+ * param: ...string     names
+ * return: V
+ * return: string 元素所在队列的key
+ * return: bool 超时返回false
+ * return: error
+ */
+func (q *BlockingQueue[V]) PollFromAny(ctx context.Context, timeout time.Duration, names ...string) (V, string, bool, error) {
+	keys, err := q.withNames(names)
+	if err != nil {
+		var v V
+		return v, "", false, err
+	}
+	if timeout <= 0 {
+		timeout = -1
+	}
+	return q.poll(ctx, timeout, keys)
+}
+
+func (q *BlockingQueue[V]) poll(ctx context.Context, timeout time.Duration, keys []string) (V, string, bool, error) {
+	var v V
+	if len(keys) == 0 {
+		keys = []string{q.name}
+	}
+	key, data, ok, err := blockingPoll(ctx, q.r, timeout, func(ctx context.Context, client gredis.UniversalClient) (string, string, error) {
+		res, err := client.BLPop(ctx, blockingPollSlice, keys...).Result()
+		if err != nil {
+			return "", "", err
+		}
+		return res[0], res[1], nil
+	})
+	if err != nil || !ok {
+		return v, key, ok, err
+	}
+	v, err = decodeAs[V](q.codec, data)
+	return v, key, err == nil, err
+}
+
+/**
+ * 按 blockingPollSlice 分段执行阻塞读取,每段结束后检查ctx
+ * 阻塞命令使用不会超时和取消的ctx,避免服务端已经取出元素后客户端因为ctx结束放弃读取结果导致元素丢失,
+ * 因此ctx结束后最多再等待一个 blockingPollSlice 才返回
+ *
+ * param: *Redis        r
+ * param: time.Duration timeout 小于0时一直等待直到ctx结束
+ * param: func          pop     使用ctx和client执行一次阻塞读取,超时返回gredis.Nil
+ * return: string 元素所在的key
+ * return: string 元素
+ * return: bool 超时返回false
+ * return: error ctx结束时返回ctx.Err()
+ */
+func blockingPoll(ctx context.Context, r *Redis, timeout time.Duration, pop func(ctx context.Context, client gredis.UniversalClient) (string, string, error)) (string, string, bool, error) {
+	var deadline time.Time
+	if timeout >= 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	client := r.blockingClient()
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", "", false, err
+		}
+		// 阻塞命令的超时时间以秒为单位,timeout的精度也只能到 blockingPollSlice
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return "", "", false, nil
+		}
+		key, data, err := pop(uncancelable{ctx}, client)
+		if err == gredis.Nil {
+			continue
+		}
+		return key, data, err == nil, err
+	}
+}
+
+// uncancelable 保留ctx中的值,去掉超时和取消
+type uncancelable struct {
+	context.Context
+}
+
+func (uncancelable) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (uncancelable) Done() <-chan struct{} {
+	return nil
+}
+
+func (uncancelable) Err() error {
+	return nil
+}
+
+/**
+ * 获取BoundedBlockingQueue,使用前需要通过TrySetCapacity设置容量
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *BoundedBlockingQueue[V]
+ */
+func GetBoundedBlockingQueue[V any](r *Redis, name string) *BoundedBlockingQueue[V] {
+	return GetBoundedBlockingQueueWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的BoundedBlockingQueue,使用前需要通过TrySetCapacity设置容量
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *BoundedBlockingQueue[V]
+ */
+func GetBoundedBlockingQueueWithCodec[V any](r *Redis, name string, c codec.Codec) *BoundedBlockingQueue[V] {
+	q := GetBlockingQueueWithCodec[V](r, name, c)
+	return &BoundedBlockingQueue[V]{object: q.object, queue: q}
+}
+
+/**
+ * 容量没有设置过时设置容量
+ *
+ * param: int64 capacity
+ * return: bool 是否设置成功
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) TrySetCapacity(ctx context.Context, capacity int64) (bool, error) {
+	n, err := boundedQueueTrySetCapacityScripter.Run(ctx, q.r, q.keys(), capacity).Int()
+	return n == 1, err
+}
+
+/**
+ * 剩余容量
+ *
+ * return: int64
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) RemainingCapacity(ctx context.Context) (int64, error) {
+	n, err := q.r.Get(ctx, q.keys()[1]).Int64()
+	if err == gredis.Nil {
+		return 0, ErrQueueCapacityNotSet
+	}
+	return n, err
+}
+
+/**
+ * 队列未满时在队尾添加元素
+ *
+ * param: V value
+ * return: bool 队列已满时返回false
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) Offer(ctx context.Context, value V) (bool, error) {
+	item, err := q.encode(value)
+	if err != nil {
+		return false, err
+	}
+	return q.offer(ctx, item)
+}
+
+/**
+ * 在队尾添加元素,队列已满时阻塞直到有空间或ctx结束
+ *
+ * param: V value
+ * return: error ctx结束时返回ctx.Err()
+ */
+func (q *BoundedBlockingQueue[V]) Put(ctx context.Context, value V) error {
+	item, err := q.encode(value)
+	if err != nil {
+		return err
+	}
+	for {
+		ok, err := q.offer(ctx, item)
+		if err != nil || ok {
+			return err
+		}
+		if err = q.await(ctx, q.keys()[2], blockingPollSlice); err != nil {
+			return err
+		}
+	}
+}
+
+/**
+ * 取出队头元素,队列为空时阻塞直到有元素或ctx结束
+ *
+ * return: V
+ * return: error ctx结束时返回ctx.Err()
+ */
+func (q *BoundedBlockingQueue[V]) Take(ctx context.Context) (V, error) {
+	v, _, err := q.poll(ctx, -1)
+	return v, err
+}
+
+/**
+ * 取出队头元素,队列为空时最多等待timeout
+ *
+ * param: time.Duration timeout 小于等于0时不等待,精度为1秒
+ * return: V
+ * return: bool 超时返回false
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) Poll(ctx context.Context, timeout time.Duration) (V, bool, error) {
+	if timeout <= 0 {
+		return decodeCmd[V](q.codec, boundedQueuePollScripter.Run(ctx, q.r, q.keys()))
+	}
+	return q.poll(ctx, timeout)
+}
+
+/**
+ * 读取队头元素,不取出
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) Peek(ctx context.Context) (V, bool, error) {
+	return q.queue.Peek(ctx)
+}
+
+/**
+ * 元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) Size(ctx context.Context) (int64, error) {
+	return q.queue.Size(ctx)
+}
+
+/**
+ * 读取全部元素
+ *
+ * return: []V
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) ReadAll(ctx context.Context) ([]V, error) {
+	return q.queue.ReadAll(ctx)
+}
+
+/**
+ * 删除队列及容量
+ *
+ * return: bool 删除前队列是否存在
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) Delete(ctx context.Context) (bool, error) {
+	ok, err := q.object.Delete(ctx)
+	if err != nil {
+		return ok, err
+	}
+	keys := q.keys()
+	return ok, q.r.Del(ctx, keys[1:]...).Err()
+}
+
+func (q *BoundedBlockingQueue[V]) offer(ctx context.Context, item string) (bool, error) {
+	n, err := boundedQueueOfferScripter.Run(ctx, q.r, q.keys(), item).Int()
+	if err != nil {
+		return false, err
+	}
+	if n < 0 {
+		return false, ErrQueueCapacityNotSet
+	}
+	return n == 1, nil
+}
+
+/**
+ * 通过脚本取出元素,队列为空时等待Offer的通知后重试
+ *
+ * param: time.Duration timeout 小于0时一直等待直到ctx结束
+ * return: V
+ * return: bool 超时返回false
+ * return: error
+ */
+func (q *BoundedBlockingQueue[V]) poll(ctx context.Context, timeout time.Duration) (V, bool, error) {
+	var deadline time.Time
+	if timeout >= 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		v, ok, err := decodeCmd[V](q.codec, boundedQueuePollScripter.Run(ctx, q.r, q.keys()))
+		if err != nil || ok {
+			return v, ok, err
+		}
+		wait := blockingPollSlice
+		if !deadline.IsZero() {
+			if wait = time.Until(deadline); wait <= 0 {
+				return v, false, nil
+			}
+		}
+		if err = q.await(ctx, q.keys()[3], wait); err != nil {
+			return v, false, err
+		}
+	}
+}
+
+/**
+ * 等待通知列表中的标记,最多等待一个 blockingPollSlice,标记只用于唤醒,丢失时等待者在超时后重试
+ *
+ * param: string        signal
+ * param: time.Duration timeout
+ * return: error ctx结束时返回ctx.Err()
+ */
+func (q *BoundedBlockingQueue[V]) await(ctx context.Context, signal string, timeout time.Duration) error {
+	if timeout > blockingPollSlice {
+		timeout = blockingPollSlice
+	}
+	_, _, _, err := blockingPoll(ctx, q.r, timeout, func(ctx context.Context, client gredis.UniversalClient) (string, string, error) {
+		res, err := client.BLPop(ctx, blockingPollSlice, signal).Result()
+		if err != nil {
+			return "", "", err
+		}
+		return res[0], res[1], nil
+	})
+	return err
+}
+
+func (q *BoundedBlockingQueue[V]) keys() []string {
+	return []string{q.name, suffixName(q.name, "permits"), suffixName(q.name, "notfull"), suffixName(q.name, "notempty")}
+}
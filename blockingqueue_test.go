@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestBoundedQueue(t *testing.T, capacity int64) *BoundedBlockingQueue[string] {
+	t.Helper()
+	q := GetBoundedBlockingQueue[string](testRedis(t), "queue")
+	if _, err := q.Delete(context.Background()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if ok, err := q.TrySetCapacity(context.Background(), capacity); err != nil || !ok {
+		t.Fatalf("TrySetCapacity() = %v, %v, want true", ok, err)
+	}
+	return q
+}
+
+func TestBoundedBlockingQueue_capacity(t *testing.T) {
+	ctx := context.Background()
+	q := newTestBoundedQueue(t, 2)
+	if ok, err := q.TrySetCapacity(ctx, 5); err != nil || ok {
+		t.Errorf("TrySetCapacity() again = %v, %v, want false", ok, err)
+	}
+	tests := []struct {
+		name      string
+		op        func() (bool, error)
+		want      bool
+		remaining int64
+	}{
+		{"offer", func() (bool, error) { return q.Offer(ctx, "a") }, true, 1},
+		{"offer last", func() (bool, error) { return q.Offer(ctx, "b") }, true, 0},
+		{"offer full", func() (bool, error) { return q.Offer(ctx, "c") }, false, 0},
+		{"poll", func() (bool, error) { _, ok, err := q.Poll(ctx, 0); return ok, err }, true, 1},
+		{"take", func() (bool, error) { _, err := q.Take(ctx); return err == nil, err }, true, 2},
+		{"poll empty", func() (bool, error) { _, ok, err := q.Poll(ctx, 0); return ok, err }, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if n, err := q.RemainingCapacity(ctx); err != nil || n != tt.remaining {
+				t.Errorf("RemainingCapacity() = %v, %v, want %v", n, err, tt.remaining)
+			}
+		})
+	}
+}
+
+func TestBoundedBlockingQueue_capacityNotSet(t *testing.T) {
+	ctx := context.Background()
+	q := GetBoundedBlockingQueue[string](testRedis(t), "queue")
+	if _, err := q.Delete(ctx); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := q.Offer(ctx, "a"); err != ErrQueueCapacityNotSet {
+		t.Errorf("Offer() error = %v, want %v", err, ErrQueueCapacityNotSet)
+	}
+	if _, err := q.RemainingCapacity(ctx); err != ErrQueueCapacityNotSet {
+		t.Errorf("RemainingCapacity() error = %v, want %v", err, ErrQueueCapacityNotSet)
+	}
+}
+
+func TestBoundedBlockingQueue_Put(t *testing.T) {
+	ctx := context.Background()
+	q := newTestBoundedQueue(t, 1)
+	if err := q.Put(ctx, "a"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- q.Put(ctx, "b")
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Put() into a full queue returned %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if v, err := q.Take(ctx); err != nil || v != "a" {
+		t.Fatalf("Take() = %v, %v, want a", v, err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Put() was not woken by Take")
+	}
+	if v, err := q.Take(ctx); err != nil || v != "b" {
+		t.Errorf("Take() = %v, %v, want b", v, err)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := q.Put(ctx, "c"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := q.Put(cctx, "d"); err != context.DeadlineExceeded {
+		t.Errorf("Put() into a full queue error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBoundedBlockingQueue_Take(t *testing.T) {
+	ctx := context.Background()
+	q := newTestBoundedQueue(t, 1)
+	start := time.Now()
+	if _, ok, err := q.Poll(ctx, time.Second); err != nil || ok {
+		t.Fatalf("Poll() from an empty queue = %v, %v, want false", ok, err)
+	}
+	if d := time.Since(start); d < time.Second || d > 3*time.Second {
+		t.Errorf("Poll() waited %v, want about 1s", d)
+	}
+	got := make(chan string, 1)
+	go func() {
+		v, _ := q.Take(ctx)
+		got <- v
+	}()
+	time.Sleep(200 * time.Millisecond)
+	if ok, err := q.Offer(ctx, "a"); err != nil || !ok {
+		t.Fatalf("Offer() = %v, %v, want true", ok, err)
+	}
+	select {
+	case v := <-got:
+		if v != "a" {
+			t.Errorf("Take() = %v, want a", v)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Take() was not woken by Offer")
+	}
+	if n, err := q.RemainingCapacity(ctx); err != nil || n != 1 {
+		t.Errorf("RemainingCapacity() = %v, %v, want 1", n, err)
+	}
+}
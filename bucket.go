@@ -0,0 +1,336 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	// ARGV[1] 为0时要求key不存在 ARGV[2] 期望的值 ARGV[3] 为0时删除key ARGV[4] 新值
+	// 更新时保留原有的过期时间
+	BucketCompareAndSetScript = `
+local v = redis.call('get', KEYS[1])
+if ARGV[1] == '0' then
+	if v ~= false then
+		return 0
+	end
+elseif v ~= ARGV[2] then
+	return 0
+end
+if ARGV[3] == '0' then
+	redis.call('del', KEYS[1])
+	return 1
+end
+local ttl = redis.call('pttl', KEYS[1])
+redis.call('set', KEYS[1], ARGV[4])
+if ttl > 0 then
+	redis.call('pexpire', KEYS[1], ttl)
+end
+return 1
+`
+	BucketGetAndDeleteScript = "local v = redis.call('get', KEYS[1]) redis.call('del', KEYS[1]) return v"
+)
+
+var (
+	bucketCompareAndSetScripter = gredis.NewScript(BucketCompareAndSetScript)
+	bucketGetAndDeleteScripter  = gredis.NewScript(BucketGetAndDeleteScript)
+)
+
+// Bucket 保存单个序列化值的对象
+type Bucket[V any] struct {
+	object
+}
+
+// Buckets 批量读写多个Bucket,集群模式下按slot拆分,Ring按hashtag拆分
+type Buckets[V any] struct {
+	r     *Redis
+	codec codec.Codec
+}
+
+/**
+ * 获取Bucket
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *Bucket[V]
+ */
+func GetBucket[V any](r *Redis, name string) *Bucket[V] {
+	return GetBucketWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的Bucket
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *Bucket[V]
+ */
+func GetBucketWithCodec[V any](r *Redis, name string, c codec.Codec) *Bucket[V] {
+	return &Bucket[V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 读取值
+ *
+ * return: V
+ * return: bool 值是否存在
+ * return: error
+ */
+func (b *Bucket[V]) Get(ctx context.Context) (V, bool, error) {
+	return b.decodeResult(b.r.Get(ctx, b.name).Result())
+}
+
+/**
+ * 设置值
+ *
+ * param: V             v
+ * param: time.Duration ttl 为0时不过期
+ * return: error
+ */
+func (b *Bucket[V]) Set(ctx context.Context, v V, ttl time.Duration) error {
+	data, err := b.encode(v)
+	if err != nil {
+		return err
+	}
+	return b.r.Set(ctx, b.name, data, ttl).Err()
+}
+
+/**
+ * 不存在时设置值
+ *
+ * param: V             v
+ * param: time.Duration ttl 为0时不过期
+ * return: bool
+ * return: error
+ */
+func (b *Bucket[V]) TrySet(ctx context.Context, v V, ttl time.Duration) (bool, error) {
+	data, err := b.encode(v)
+	if err != nil {
+		return false, err
+	}
+	return b.r.SetNX(ctx, b.name, data, ttl).Result()
+}
+
+/**
+ * 已经存在时设置值
+ *
+ * param: V             v
+ * param: time.Duration ttl 为0时不过期
+ * return: bool
+ * return: error
+ */
+func (b *Bucket[V]) SetIfExists(ctx context.Context, v V, ttl time.Duration) (bool, error) {
+	data, err := b.encode(v)
+	if err != nil {
+		return false, err
+	}
+	return b.r.SetXX(ctx, b.name, data, ttl).Result()
+}
+
+/**
+ * 当前值等于expect时设置为update,expect为nil表示要求值不存在,update为nil表示删除
+ *
+ * param: *V expect
+ * param: *V update
+ * return: bool
+ * return: error
+ */
+func (b *Bucket[V]) CompareAndSet(ctx context.Context, expect, update *V) (bool, error) {
+	args := []interface{}{0, "", 0, ""}
+	var err error
+	if expect != nil {
+		args[0] = 1
+		if args[1], err = b.encode(*expect); err != nil {
+			return false, err
+		}
+	}
+	if update != nil {
+		args[2] = 1
+		if args[3], err = b.encode(*update); err != nil {
+			return false, err
+		}
+	}
+	res, err := bucketCompareAndSetScripter.Run(ctx, b.r, []string{b.name}, args...).Int()
+	return res == 1, err
+}
+
+/**
+ * 设置新值并读取旧值
+ *
+ * param: V v
+ * return: V 旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (b *Bucket[V]) GetAndSet(ctx context.Context, v V) (V, bool, error) {
+	data, err := b.encode(v)
+	if err != nil {
+		var old V
+		return old, false, err
+	}
+	return b.decodeResult(b.r.GetSet(ctx, b.name, data).Result())
+}
+
+/**
+ * 删除并读取删除前的值
+ *
+ * return: V 旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (b *Bucket[V]) GetAndDelete(ctx context.Context) (V, bool, error) {
+	return decodeCmd[V](b.codec, bucketGetAndDeleteScripter.Run(ctx, b.r, []string{b.name}))
+}
+
+func (b *Bucket[V]) decodeResult(data string, err error) (V, bool, error) {
+	var v V
+	if err == gredis.Nil {
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	v, err = decodeAs[V](b.codec, data)
+	return v, err == nil, err
+}
+
+/**
+ * 获取Buckets
+ *
+ * param: *Redis r
+ * return: *Buckets[V]
+ */
+func GetBuckets[V any](r *Redis) *Buckets[V] {
+	return GetBucketsWithCodec[V](r, nil)
+}
+
+/**
+ * 获取使用指定codec的Buckets
+ *
+ * param: *Redis      r
+ * param: codec.Codec c
+ * return: *Buckets[V]
+ */
+func GetBucketsWithCodec[V any](r *Redis, c codec.Codec) *Buckets[V] {
+	if c == nil {
+		c = r.DefaultCodec()
+	}
+	return &Buckets[V]{r: r, codec: c}
+}
+
+/**
+ * 批量读取
+ *
+ * param: ...string names
+ * return: map[string]V 名称到值的映射,不存在的名称不包含在内
+ * return: error
+ */
+func (b *Buckets[V]) Get(ctx context.Context, names ...string) (map[string]V, error) {
+	values := make(map[string]V, len(names))
+	if len(names) == 0 {
+		return values, nil
+	}
+	keys := make([]string, 0, len(names))
+	byKey := make(map[string]string, len(names))
+	for _, name := range names {
+		key := b.r.namespaced(name)
+		byKey[key] = name
+		keys = append(keys, key)
+	}
+	groups := b.r.groupBySlot(keys)
+	cmds := make([]*gredis.SliceCmd, len(groups))
+	_, err := b.r.Pipelined(ctx, func(pipe gredis.Pipeliner) error {
+		for i, group := range groups {
+			cmds[i] = pipe.MGet(ctx, group...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, group := range groups {
+		for j, v := range cmds[i].Val() {
+			data, ok := v.(string)
+			if !ok {
+				continue
+			}
+			if values[byKey[group[j]]], err = decodeAs[V](b.codec, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+/**
+ * 批量设置
+ *
+ * param: map[string]V values
+ * return: error
+ */
+func (b *Buckets[V]) Set(ctx context.Context, values map[string]V) error {
+	if len(values) == 0 {
+		return nil
+	}
+	data, keys, err := b.encode(values)
+	if err != nil {
+		return err
+	}
+	groups := b.r.groupBySlot(keys)
+	_, err = b.r.Pipelined(ctx, func(pipe gredis.Pipeliner) error {
+		for _, group := range groups {
+			pipe.MSet(ctx, pairs(group, data)...)
+		}
+		return nil
+	})
+	return err
+}
+
+/**
+ * 所有名称都不存在时批量设置,集群模式下所有名称需要位于同一个slot,Ring需要hashtag相同
+ *
+ * param: map[string]V values
+ * return: bool
+ * return: error
+ */
+func (b *Buckets[V]) TrySet(ctx context.Context, values map[string]V) (bool, error) {
+	if len(values) == 0 {
+		return true, nil
+	}
+	data, keys, err := b.encode(values)
+	if err != nil {
+		return false, err
+	}
+	if err = b.r.checkSameSlot(keys...); err != nil {
+		return false, err
+	}
+	return b.r.MSetNX(ctx, pairs(keys, data)...).Result()
+}
+
+func (b *Buckets[V]) encode(values map[string]V) (map[string]string, []string, error) {
+	data := make(map[string]string, len(values))
+	keys := make([]string, 0, len(values))
+	for name, v := range values {
+		encoded, err := b.codec.Encode(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := b.r.namespaced(name)
+		data[key] = string(encoded)
+		keys = append(keys, key)
+	}
+	return data, keys, nil
+}
+
+func pairs(keys []string, data map[string]string) []interface{} {
+	args := make([]interface{}, 0, len(keys)*2)
+	for _, key := range keys {
+		args = append(args, key, data[key])
+	}
+	return args
+}
@@ -0,0 +1,315 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ainiaa/catutil/v2/rediscat"
+	gredis "github.com/go-redis/redis/v8"
+)
+
+const ClientCacheInvalidateChannel = "__redis__:invalidate"
+
+// ClientCacheOptions 客户端缓存的配置
+type ClientCacheOptions struct {
+	EvictionPolicy EvictionPolicy
+	CacheSize      int           // 最大缓存的key数,0表示不限制
+	TimeToLive     time.Duration // 缓存的存活时间,0表示不过期,依赖服务端的失效通知
+	PingInterval   time.Duration // 订阅连接的检测间隔,0时使用 DefaultPubSubPingInterval
+}
+
+// ClientCache 基于redis 6 CLIENT TRACKING的客户端缓存
+// 使用RESP2的重定向模式:独立的订阅连接接收 __redis__:invalidate 通知,读取连接开启tracking并把通知重定向到订阅连接
+// 订阅连接断开或读取连接出现网络错误时清空缓存,订阅连接重连后重建读取连接
+type ClientCache struct {
+	r            *Redis
+	opt          gredis.Options
+	pingInterval time.Duration
+	cache        *localCache[string, *clientCacheValue]
+	mu           sync.RWMutex
+	data         *gredis.Client
+	sub          *gredis.Client
+	pubsub       *gredis.PubSub
+	subID        int64
+	tracking     int32
+	cancel       context.CancelFunc
+	done         chan struct{}
+	once         sync.Once
+}
+
+// clientCacheValue 一个key的缓存内容,写入后不再修改
+type clientCacheValue struct {
+	loaded bool    // 是否缓存了GET的结果
+	value  *string // nil表示key不存在
+	fields map[string]*string
+}
+
+/**
+ * 开启客户端缓存,只支持单机模式
+ *
+ * param: ClientCacheOptions opts
+ * return: *ClientCache
+ * return: error
+ */
+func (r *Redis) EnableClientCache(ctx context.Context, opts ClientCacheOptions) (*ClientCache, error) {
+	client, ok := r.UniversalClient.(*gredis.Client)
+	if !ok {
+		return nil, ErrClientCacheUnsupported
+	}
+	c := &ClientCache{
+		r:            r,
+		opt:          *client.Options(),
+		pingInterval: opts.PingInterval,
+		cache:        newLocalCache[string, *clientCacheValue](opts.EvictionPolicy, opts.CacheSize, opts.TimeToLive, 0),
+		done:         make(chan struct{}),
+	}
+	subOpt := c.opt
+	onConnect := c.opt.OnConnect
+	subOpt.OnConnect = func(ctx context.Context, cn *gredis.Conn) error {
+		if onConnect != nil {
+			if err := onConnect(ctx, cn); err != nil {
+				return err
+			}
+		}
+		id, err := cn.ClientID(ctx).Result()
+		if err != nil {
+			return err
+		}
+		atomic.StoreInt64(&c.subID, id)
+		return nil
+	}
+	c.sub = gredis.NewClient(&subOpt)
+	c.pubsub = c.sub.Subscribe(ctx, ClientCacheInvalidateChannel)
+	if _, err := c.pubsub.Receive(ctx); err != nil { // 等待订阅确认,同时得到订阅连接的id
+		_ = c.pubsub.Close()
+		_ = c.sub.Close()
+		return nil, err
+	}
+	c.data = c.newDataClient()
+	atomic.StoreInt32(&c.tracking, 1)
+	var listenCtx context.Context
+	listenCtx, c.cancel = context.WithCancel(context.Background())
+	go c.listen(listenCtx)
+	return c, nil
+}
+
+/**
+ * 读取字符串,优先读取缓存
+ *
+ * param: string key
+ * return: string
+ * return: bool 是否存在
+ * return: error
+ */
+func (c *ClientCache) Get(ctx context.Context, key string) (string, bool, error) {
+	prev, ok := c.cache.get(key)
+	if ok && prev.loaded {
+		return deref(prev.value)
+	}
+	tracking, ver := c.isTracking(), c.cache.version()
+	v, err := c.client().Get(ctx, key).Result()
+	value, err := optional(v, err)
+	if err != nil {
+		return "", false, err
+	}
+	if tracking {
+		entry := &clientCacheValue{loaded: true, value: value}
+		if prev != nil {
+			entry.fields = prev.fields
+		}
+		c.cache.putIfVersion(key, entry, ver)
+	}
+	return deref(value)
+}
+
+/**
+ * 读取hash的field,优先读取缓存
+ *
+ * param: string key
+ * param: string field
+ * return: string
+ * return: bool 是否存在
+ * return: error
+ */
+func (c *ClientCache) HGet(ctx context.Context, key, field string) (string, bool, error) {
+	prev, ok := c.cache.get(key)
+	if ok {
+		if value, ok := prev.fields[field]; ok {
+			return deref(value)
+		}
+	}
+	tracking, ver := c.isTracking(), c.cache.version()
+	v, err := c.client().HGet(ctx, key, field).Result()
+	value, err := optional(v, err)
+	if err != nil {
+		return "", false, err
+	}
+	if tracking {
+		entry := &clientCacheValue{fields: map[string]*string{field: value}}
+		if prev != nil {
+			entry.loaded, entry.value = prev.loaded, prev.value
+			for f, v := range prev.fields {
+				entry.fields[f] = v
+			}
+		}
+		c.cache.putIfVersion(key, entry, ver)
+	}
+	return deref(value)
+}
+
+/**
+ * 缓存的key数
+ *
+ * return: int
+ */
+func (c *ClientCache) Size() int {
+	return c.cache.len()
+}
+
+/**
+ * 清空缓存
+ */
+func (c *ClientCache) Clear() {
+	c.cache.clear()
+}
+
+/**
+ * 关闭订阅连接和读取连接,可以重复调用
+ *
+ * return: error
+ */
+func (c *ClientCache) Close() error {
+	var err error
+	c.once.Do(func() {
+		c.cancel()
+		err = c.pubsub.Close()
+		<-c.done
+		c.mu.Lock()
+		_ = c.data.Close()
+		c.mu.Unlock()
+		_ = c.sub.Close()
+		c.cache.clear()
+	})
+	return err
+}
+
+func (c *ClientCache) client() *gredis.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data
+}
+
+func (c *ClientCache) isTracking() bool {
+	return atomic.LoadInt32(&c.tracking) == 1
+}
+
+func (c *ClientCache) newDataClient() *gredis.Client {
+	opt := c.opt
+	subID := atomic.LoadInt64(&c.subID)
+	onConnect := c.opt.OnConnect
+	opt.OnConnect = func(ctx context.Context, cn *gredis.Conn) error {
+		if onConnect != nil {
+			if err := onConnect(ctx, cn); err != nil {
+				return err
+			}
+		}
+		// 新连接可能是替换了断开的连接,断开连接上读取的key已经不再被跟踪
+		c.cache.clear()
+		cmd := gredis.NewStatusCmd(ctx, "client", "tracking", "on", "redirect", subID)
+		_ = cn.Process(ctx, cmd)
+		return cmd.Err()
+	}
+	opt.IdleTimeout = -1 // 关闭空闲连接也会丢失跟踪状态
+	opt.MaxConnAge = 0
+	client := gredis.NewClient(&opt)
+	client.AddHook(rediscat.RedisTraceHook{})
+	client.AddHook(clientCacheHook{c: c})
+	return client
+}
+
+func (c *ClientCache) listen(ctx context.Context) {
+	defer close(c.done)
+	listenPubSub(ctx, c.pubsub, c.pingInterval, pubSubHandler{
+		onMessage: func(msg *gredis.Message) {
+			// RESP2下失效的key以数组形式放在消息内容中
+			for _, key := range msg.PayloadSlice {
+				c.cache.remove(key)
+			}
+			if msg.Payload != "" {
+				c.cache.remove(msg.Payload)
+			}
+		},
+		onDisconnect: func() {
+			atomic.StoreInt32(&c.tracking, 0)
+			c.cache.clear()
+		},
+		onReconnect: c.resetDataClient,
+	})
+}
+
+/**
+ * 订阅连接重连后id发生变化,重建读取连接重定向到新的订阅连接
+ */
+func (c *ClientCache) resetDataClient() {
+	client := c.newDataClient()
+	c.mu.Lock()
+	old := c.data
+	c.data = client
+	c.mu.Unlock()
+	_ = old.Close()
+	c.cache.clear()
+	atomic.StoreInt32(&c.tracking, 1)
+}
+
+// clientCacheHook 读取连接出现网络错误时连接会被丢弃,清空缓存
+type clientCacheHook struct {
+	c *ClientCache
+}
+
+func (h clientCacheHook) BeforeProcess(ctx context.Context, cmd gredis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h clientCacheHook) AfterProcess(ctx context.Context, cmd gredis.Cmder) error {
+	h.check(cmd.Err())
+	return nil
+}
+
+func (h clientCacheHook) BeforeProcessPipeline(ctx context.Context, cmds []gredis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h clientCacheHook) AfterProcessPipeline(ctx context.Context, cmds []gredis.Cmder) error {
+	for _, cmd := range cmds {
+		h.check(cmd.Err())
+	}
+	return nil
+}
+
+func (h clientCacheHook) check(err error) {
+	if err == nil || err == gredis.Nil {
+		return
+	}
+	if _, ok := err.(gredis.Error); !ok {
+		h.c.cache.clear()
+	}
+}
+
+func optional(v string, err error) (*string, error) {
+	if err == gredis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func deref(v *string) (string, bool, error) {
+	if v == nil {
+		return "", false, nil
+	}
+	return *v, true, nil
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+// redisError 服务端返回的错误,连接仍然可用
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+func (redisError) RedisError() {}
+
+func TestClientCacheHook_check(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		clear bool
+	}{
+		{"ok", nil, false},
+		{"nil", gredis.Nil, false},
+		{"redis error", redisError("WRONGTYPE"), false},
+		{"network error", errors.New("read: connection reset by peer"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ClientCache{cache: newLocalCache[string, *clientCacheValue](EvictionPolicyNone, 0, 0, 0)}
+			c.cache.put("key", &clientCacheValue{loaded: true})
+			clientCacheHook{c: c}.check(tt.err)
+			if got := c.Size() == 0; got != tt.clear {
+				t.Errorf("check(%v) cleared = %v, want %v", tt.err, got, tt.clear)
+			}
+		})
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	ctx := context.Background()
+	r := testRedis(t)
+	if err := r.Do(ctx, "client", "tracking", "off").Err(); err != nil {
+		t.Skipf("client tracking is not supported: %v", err)
+	}
+	c, err := r.EnableClientCache(ctx, ClientCacheOptions{})
+	if err != nil {
+		t.Fatalf("EnableClientCache() error = %v", err)
+	}
+	defer c.Close()
+	key := r.namespaced("key")
+	if err := r.Set(ctx, key, "a", 0).Err(); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if v, ok, err := c.Get(ctx, key); err != nil || !ok || v != "a" {
+		t.Fatalf("Get() = %v, %v, %v, want a", v, ok, err)
+	}
+	if c.Size() != 1 {
+		t.Fatalf("Size() = %v, want 1", c.Size())
+	}
+	if err := r.Set(ctx, key, "b", 0).Err(); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	// 失效通知是异步的
+	deadline := time.Now().Add(time.Second)
+	for c.Size() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v, ok, err := c.Get(ctx, key); err != nil || !ok || v != "b" {
+		t.Errorf("Get() after the key changed = %v, %v, %v, want b", v, ok, err)
+	}
+	if err := r.Del(ctx, key).Err(); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package codec
+
+import (
+	"github.com/ainiaa/go-exception"
+)
+
+const (
+	NameJSON    = "json"
+	NameGob     = "gob"
+	NameMsgPack = "msgpack"
+	NameString  = "string"
+	NameBytes   = "bytes"
+)
+
+var (
+	ErrCodecUnknown       = exception.New(-101, "unknown codec")
+	ErrCompressionUnknown = exception.New(-102, "unknown compression algorithm")
+	ErrUnsupportedValue   = exception.New(-103, "codec does not support the value type")
+)
+
+// Codec 对象值的序列化方式
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	// v 必须是指针
+	Decode(data []byte, v interface{}) error
+}
+
+var codecs = map[string]Codec{
+	NameJSON:    JSON,
+	NameGob:     Gob,
+	NameMsgPack: MsgPack,
+	NameString:  String,
+	NameBytes:   Bytes,
+}
+
+/**
+ * 根据名称获取内置的codec,名称为空时使用json
+ *
+ * param: string name
+ * return: Codec
+ * return: error
+ */
+func Get(name string) (Codec, error) {
+	if name == "" {
+		return JSON, nil
+	}
+	c, ok := codecs[name]
+	if !ok {
+		return nil, ErrCodecUnknown
+	}
+	return c, nil
+}
+
+/**
+ * 根据名称和压缩算法获取codec,压缩算法为空时不压缩
+ *
+ * param: string name
+ * param: string compression
+ * return: Codec
+ * return: error
+ */
+func New(name, compression string) (Codec, error) {
+	c, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if compression == "" {
+		return c, nil
+	}
+	return NewCompression(c, compression)
+}
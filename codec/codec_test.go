@@ -0,0 +1,66 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testValue struct {
+	Name  string
+	Count int
+	Tags  []string
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	want := testValue{Name: "go-redisson", Count: 3, Tags: []string{"a", "b"}}
+	for _, name := range []string{NameJSON, NameGob, NameMsgPack} {
+		for _, compression := range []string{"", CompressionGzip, CompressionSnappy, CompressionZstd} {
+			t.Run(name+"-"+compression, func(t *testing.T) {
+				c, err := New(name, compression)
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+				data, err := c.Encode(want)
+				if err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+				var got testValue
+				if err = c.Decode(data, &got); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Decode() got = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestCodec_Raw(t *testing.T) {
+	for _, c := range []Codec{String, Bytes} {
+		data, err := c.Encode("abc")
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		var s string
+		var b []byte
+		if err = c.Decode(data, &s); err != nil || s != "abc" {
+			t.Errorf("Decode() string = %q, err = %v", s, err)
+		}
+		if err = c.Decode(data, &b); err != nil || string(b) != "abc" {
+			t.Errorf("Decode() bytes = %q, err = %v", b, err)
+		}
+		if _, err = c.Encode(1); err != ErrUnsupportedValue {
+			t.Errorf("Encode() error = %v, want %v", err, ErrUnsupportedValue)
+		}
+	}
+}
+
+func TestNew_Unknown(t *testing.T) {
+	if _, err := New("xml", ""); err != ErrCodecUnknown {
+		t.Errorf("New() error = %v, want %v", err, ErrCodecUnknown)
+	}
+	if _, err := New(NameJSON, "lz4"); err != ErrCompressionUnknown {
+		t.Errorf("New() error = %v, want %v", err, ErrCompressionUnknown)
+	}
+}
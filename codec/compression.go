@@ -0,0 +1,108 @@
+package codec
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionGzip   = "gzip"
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// compression 在另一个codec的基础上压缩序列化后的数据
+type compression struct {
+	Codec
+	compress   func([]byte) ([]byte, error)
+	decompress func([]byte) ([]byte, error)
+}
+
+/**
+ * 创建压缩codec
+ *
+ * param: Codec  c
+ * param: string algorithm gzip snappy zstd
+ * return: Codec
+ * return: error
+ */
+func NewCompression(c Codec, algorithm string) (Codec, error) {
+	switch algorithm {
+	case CompressionGzip:
+		return &compression{Codec: c, compress: gzipCompress, decompress: gzipDecompress}, nil
+	case CompressionSnappy:
+		return &compression{Codec: c, compress: snappyCompress, decompress: snappyDecompress}, nil
+	case CompressionZstd:
+		zstdOnce.Do(func() {
+			zstdEncoder, _ = zstd.NewWriter(nil)
+			zstdDecoder, _ = zstd.NewReader(nil)
+		})
+		return &compression{Codec: c, compress: zstdCompress, decompress: zstdDecompress}, nil
+	default:
+		return nil, ErrCompressionUnknown
+	}
+}
+
+func (c *compression) Encode(v interface{}) ([]byte, error) {
+	data, err := c.Codec.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.compress(data)
+}
+
+func (c *compression) Decode(data []byte, v interface{}) error {
+	data, err := c.decompress(data)
+	if err != nil {
+		return err
+	}
+	return c.Codec.Decode(data, v)
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func snappyCompress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func snappyDecompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
+
+func zstdCompress(data []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func zstdDecompress(data []byte) ([]byte, error) {
+	return zstdDecoder.DecodeAll(data, nil)
+}
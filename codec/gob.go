@@ -0,0 +1,23 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Gob 使用encoding/gob序列化,接口类型的值需要先调用gob.Register注册
+var Gob Codec = gobCodec{}
+
+type gobCodec struct{}
+
+func (gobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Decode(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
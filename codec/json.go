@@ -0,0 +1,18 @@
+package codec
+
+import (
+	jsoniter "github.com/json-iterator/go"
+)
+
+// JSON 使用json-iterator序列化,与标准库encoding/json兼容
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(v interface{}) ([]byte, error) {
+	return jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(v)
+}
+
+func (jsonCodec) Decode(data []byte, v interface{}) error {
+	return jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, v)
+}
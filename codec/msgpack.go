@@ -0,0 +1,18 @@
+package codec
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgPack 使用MessagePack序列化
+var MsgPack Codec = msgpackCodec{}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Encode(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Decode(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
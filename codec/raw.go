@@ -0,0 +1,63 @@
+package codec
+
+var (
+	// String 原样保存字符串,只支持string和[]byte
+	String Codec = stringCodec{}
+	// Bytes 原样保存字节数组,只支持[]byte和string
+	Bytes Codec = bytesCodec{}
+)
+
+type stringCodec struct{}
+
+type bytesCodec struct{}
+
+func (stringCodec) Encode(v interface{}) ([]byte, error) {
+	return encodeRaw(v)
+}
+
+func (stringCodec) Decode(data []byte, v interface{}) error {
+	switch p := v.(type) {
+	case *string:
+		*p = string(data)
+	case *[]byte:
+		*p = append((*p)[:0], data...)
+	case *interface{}:
+		*p = string(data)
+	default:
+		return ErrUnsupportedValue
+	}
+	return nil
+}
+
+func (bytesCodec) Encode(v interface{}) ([]byte, error) {
+	return encodeRaw(v)
+}
+
+func (bytesCodec) Decode(data []byte, v interface{}) error {
+	switch p := v.(type) {
+	case *[]byte:
+		*p = append((*p)[:0], data...)
+	case *string:
+		*p = string(data)
+	case *interface{}:
+		*p = append([]byte(nil), data...)
+	default:
+		return ErrUnsupportedValue
+	}
+	return nil
+}
+
+func encodeRaw(v interface{}) ([]byte, error) {
+	switch s := v.(type) {
+	case string:
+		return []byte(s), nil
+	case []byte:
+		return s, nil
+	case *string:
+		return []byte(*s), nil
+	case *[]byte:
+		return *s, nil
+	default:
+		return nil, ErrUnsupportedValue
+	}
+}
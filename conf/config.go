@@ -1,8 +1,11 @@
 package conf
 
 type Config struct {
-	ConnType string         `json:"conn_type"` // cluster alone  sentinel
-	Alone    AloneConfig    `json:"alone"`
-	Cluster  ClusterConfig  `json:"cluster"`
-	Sentinel SentinelConfig `json:"sentinel"`
+	ConnType    string         `json:"conn_type"`   // cluster alone  sentinel
+	Namespace   string         `json:"namespace"`   // 所有对象key的前缀,为空时不加前缀
+	Codec       string         `json:"codec"`       // json gob msgpack string bytes,默认json
+	Compression string         `json:"compression"` // gzip snappy zstd,默认不压缩
+	Alone       AloneConfig    `json:"alone"`
+	Cluster     ClusterConfig  `json:"cluster"`
+	Sentinel    SentinelConfig `json:"sentinel"`
 }
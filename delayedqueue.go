@@ -0,0 +1,265 @@
+package redis
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+	"github.com/hashicorp/go-uuid"
+)
+
+const (
+	// 元素前面加上随机前缀,同一个值可以多次添加
+	delayedQueuePrefixLen = 16
+	// 其他实例正在转移时的重试间隔
+	delayedQueueRetryDelay = 100 * time.Millisecond
+	// 出错后的重试间隔
+	delayedQueueErrorDelay = time.Second
+
+	// 到期时间都使用服务端时间,避免多个实例之间的时钟偏差
+	// KEYS[1] 延迟队列 KEYS[2] 通知channel ARGV[1] 延迟毫秒数 ARGV[2] 元素
+	// 新元素的到期时间最早时通知转移任务提前唤醒
+	DelayedQueueOfferScript = serverNowScript + `
+local expireAt = now + tonumber(ARGV[1])
+redis.call('zadd', KEYS[1], expireAt, ARGV[2])
+local first = redis.call('zrange', KEYS[1], 0, 0)
+if first[1] == ARGV[2] then
+	redis.call('publish', KEYS[2], expireAt)
+end
+`
+	// KEYS[1] 延迟队列 KEYS[2] 目标队列 ARGV[1] 随机前缀的长度
+	// 每次最多转移100个到期元素,返回距离下一个元素到期的毫秒数,没有元素时返回-1
+	DelayedQueueTransferScript = serverNowScript + `
+local due = redis.call('zrangebyscore', KEYS[1], '-inf', now, 'limit', 0, 100)
+for _, v in ipairs(due) do
+	redis.call('rpush', KEYS[2], string.sub(v, tonumber(ARGV[1]) + 1))
+	redis.call('zrem', KEYS[1], v)
+end
+local next = redis.call('zrange', KEYS[1], 0, 0, 'withscores')
+if next[1] == nil then
+	return -1
+end
+return math.max(tonumber(next[2]) - now, 0)
+`
+	// KEYS[1] 延迟队列 ARGV[1] 随机前缀的长度 ARGV[2] 元素 ARGV[3] 为1时删除全部相同的元素,否则只删除一个
+	// 元素带有随机前缀,只能遍历整个延迟队列比较,时间复杂度为O(N)
+	DelayedQueueRemoveScript = `
+local n = 0
+for _, v in ipairs(redis.call('zrange', KEYS[1], 0, -1)) do
+	if string.sub(v, tonumber(ARGV[1]) + 1) == ARGV[2] then
+		redis.call('zrem', KEYS[1], v)
+		n = n + 1
+		if ARGV[3] ~= '1' then
+			break
+		end
+	end
+end
+return n
+`
+)
+
+var (
+	delayedQueueOfferScripter    = gredis.NewScript(DelayedQueueOfferScript)
+	delayedQueueTransferScripter = gredis.NewScript(DelayedQueueTransferScript)
+	delayedQueueRemoveScripter   = gredis.NewScript(DelayedQueueRemoveScript)
+)
+
+// DelayedQueue 延迟队列,元素按到期时间保存在有序集合中,到期后由后台任务转移到目标队列
+// 多个实例同时运行时通过分布式锁保证同一时刻只有一个实例在转移
+type DelayedQueue[V any] struct {
+	object
+	dest    string
+	channel string
+	pubsub  *gredis.PubSub
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+/**
+ * 获取转移到dest的DelayedQueue并启动后台转移任务,使用完后需要调用Close
+ *
+ * param: *Redis    r
+ * param: *Queue[V] dest 目标队列,使用目标队列的codec
+ * return: *DelayedQueue[V]
+ * return: error
+ */
+func GetDelayedQueue[V any](ctx context.Context, r *Redis, dest *Queue[V]) (*DelayedQueue[V], error) {
+	q := &DelayedQueue[V]{
+		object:  object{r: r, name: suffixName(dest.name, "delayed"), codec: dest.codec},
+		dest:    dest.name,
+		channel: suffixName(dest.name, "delayed:channel"),
+		wake:    make(chan struct{}, 1),
+	}
+	q.pubsub = r.Subscribe(ctx, q.channel)
+	if _, err := q.pubsub.Receive(ctx); err != nil { // 等待订阅确认
+		_ = q.pubsub.Close()
+		return nil, err
+	}
+	var runCtx context.Context
+	runCtx, q.cancel = context.WithCancel(context.Background())
+	q.wg.Add(2)
+	go q.listen(runCtx)
+	go q.run(runCtx)
+	return q, nil
+}
+
+/**
+ * 添加元素,delay后转移到目标队列
+ *
+ * param: V             value
+ * param: time.Duration delay
+ * return: error
+ */
+func (q *DelayedQueue[V]) Offer(ctx context.Context, value V, delay time.Duration) error {
+	item, err := q.encode(value)
+	if err != nil {
+		return err
+	}
+	prefix, err := uuid.GenerateRandomBytes(delayedQueuePrefixLen / 2)
+	if err != nil {
+		return err
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	err = delayedQueueOfferScripter.Run(ctx, q.r, []string{q.name, q.channel}, delay.Milliseconds(), hex.EncodeToString(prefix)+item).Err()
+	if err == gredis.Nil {
+		return nil
+	}
+	return err
+}
+
+/**
+ * 删除还没有到期的元素,需要遍历整个延迟队列,时间复杂度为O(N)
+ *
+ * param: V    value
+ * param: bool all 是否删除全部相同的元素,否则只删除到期时间最早的一个
+ * return: int64 删除的数量
+ * return: error
+ */
+func (q *DelayedQueue[V]) Remove(ctx context.Context, value V, all bool) (int64, error) {
+	item, err := q.encode(value)
+	if err != nil {
+		return 0, err
+	}
+	flag := 0
+	if all {
+		flag = 1
+	}
+	return delayedQueueRemoveScripter.Run(ctx, q.r, []string{q.name}, delayedQueuePrefixLen, item, flag).Int64()
+}
+
+/**
+ * 还没有到期的元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (q *DelayedQueue[V]) Size(ctx context.Context) (int64, error) {
+	return q.r.ZCard(ctx, q.name).Result()
+}
+
+/**
+ * 按到期时间顺序读取全部还没有到期的元素
+ *
+ * return: []V
+ * return: error
+ */
+func (q *DelayedQueue[V]) ReadAll(ctx context.Context) ([]V, error) {
+	items, err := q.r.ZRange(ctx, q.name, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		if items[i], err = stripPrefix(item, delayedQueuePrefixLen); err != nil {
+			return nil, err
+		}
+	}
+	return decodeValues[V](q.codec)(items, nil)
+}
+
+/**
+ * 停止后台转移任务,可以重复调用,没有到期的元素保留在redis中
+ */
+func (q *DelayedQueue[V]) Close() {
+	q.once.Do(func() {
+		q.cancel()
+		_ = q.pubsub.Close()
+		q.wg.Wait()
+	})
+}
+
+func (q *DelayedQueue[V]) listen(ctx context.Context) {
+	defer q.wg.Done()
+	listenPubSub(ctx, q.pubsub, 0, pubSubHandler{
+		onMessage: func(*gredis.Message) {
+			q.notify()
+		},
+		onReconnect: q.notify, // 断开期间的通知可能丢失
+	})
+}
+
+func (q *DelayedQueue[V]) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+/**
+ * 循环转移到期元素,在下一个元素到期或者收到通知时唤醒
+ */
+func (q *DelayedQueue[V]) run(ctx context.Context) {
+	defer q.wg.Done()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		case <-timer.C:
+		}
+		next, err := q.transfer(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			timer.Reset(delayedQueueErrorDelay)
+		case next >= 0:
+			timer.Reset(time.Duration(next) * time.Millisecond)
+		}
+	}
+}
+
+/**
+ * 获取锁后转移到期元素,其他实例正在转移时稍后重试,其他错误返回给调用方按 delayedQueueErrorDelay 重试
+ *
+ * return: int64 距离下一个元素到期的毫秒数,没有元素时返回-1
+ * return: error
+ */
+func (q *DelayedQueue[V]) transfer(ctx context.Context) (int64, error) {
+	lockName := suffixName(q.dest, "delayed:transfer")
+	lockId, err := q.r.LockSingle(ctx, lockName, 1)
+	if err == ErrExitsLock {
+		return delayedQueueRetryDelay.Milliseconds(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = q.r.Unlock(context.Background(), lockName, lockId)
+	}()
+	return delayedQueueTransferScripter.Run(ctx, q.r, []string{q.name, q.dest}, delayedQueuePrefixLen).Int64()
+}
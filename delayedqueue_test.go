@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDelayedQueue(t *testing.T) {
+	ctx := context.Background()
+	r := testRedis(t)
+	dest := GetQueue[string](r, "dest")
+	if _, err := dest.Delete(ctx); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	q, err := GetDelayedQueue[string](ctx, r, dest)
+	if err != nil {
+		t.Fatalf("GetDelayedQueue() error = %v", err)
+	}
+	defer q.Close()
+	if _, err := q.Delete(ctx); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	offers := []struct {
+		value string
+		delay time.Duration
+	}{
+		{"a", 200 * time.Millisecond},
+		{"b", time.Hour},
+		{"b", time.Hour},
+		{"c", 100 * time.Millisecond},
+		{"d", time.Hour},
+	}
+	for _, o := range offers {
+		if err := q.Offer(ctx, o.value, o.delay); err != nil {
+			t.Fatalf("Offer() error = %v", err)
+		}
+	}
+	removes := []struct {
+		value string
+		all   bool
+		want  int64
+	}{
+		{"b", true, 2},
+		{"d", false, 1},
+		{"e", true, 0},
+	}
+	for _, tt := range removes {
+		if n, err := q.Remove(ctx, tt.value, tt.all); err != nil || n != tt.want {
+			t.Errorf("Remove(%v) = %v, %v, want %v", tt.value, n, err, tt.want)
+		}
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if n, _ := dest.Size(ctx); n == 2 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	got, err := dest.ReadAll(ctx)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := []string{"c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("transferred = %v, want %v", got, want)
+	}
+	if n, err := q.Size(ctx); err != nil || n != 0 {
+		t.Errorf("Size() = %v, %v, want 0", n, err)
+	}
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// evictionTask 定期清理过期数据的后台任务,通过分布式锁保证同一时刻只有一个实例在清理
+type evictionTask struct {
+	r        *Redis
+	lockName string
+	interval time.Duration
+	evict    func(ctx context.Context) error
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+/**
+ * 启动清理任务
+ *
+ * param: string                          lockName
+ * param: time.Duration                   interval
+ * param: func(ctx context.Context) error evict
+ * return: *evictionTask
+ */
+func (r *Redis) startEviction(lockName string, interval time.Duration, evict func(ctx context.Context) error) *evictionTask {
+	t := &evictionTask{
+		r:        r,
+		lockName: lockName,
+		interval: interval,
+		evict:    evict,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+func (t *evictionTask) run() {
+	defer close(t.done)
+	lockTime := int64(t.interval / time.Second)
+	if lockTime < 1 {
+		lockTime = 1
+	}
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			ctx := context.Background()
+			lockId, err := t.r.LockSingle(ctx, t.lockName, lockTime)
+			if err != nil { // 其他实例正在清理
+				continue
+			}
+			_ = t.evict(ctx)
+			_ = t.r.Unlock(ctx, t.lockName, lockId)
+		}
+	}
+}
+
+/**
+ * 停止清理任务,可以重复调用
+ */
+func (t *evictionTask) close() {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		close(t.stop)
+		<-t.done
+	})
+}
@@ -14,3 +14,31 @@ var (
 	ErrAcquiredLock        = exception.New(-5, "acquire lock error")
 	ErrAcquiredLockTimeout = exception.New(-6, "acquire lock timeout error")
 )
+var (
+	ErrRateLimiterNotInit = exception.New(-7, "rate limiter is not initialized,please set rate first")
+	ErrPermitsExceedRate  = exception.New(-8, "requested permits exceed the rate")
+	ErrInvalidPermits     = exception.New(-9, "permits must be positive")
+)
+var (
+	ErrNoWorkerId      = exception.New(-10, "no free snowflake worker id")
+	ErrWorkerLeaseLost = exception.New(-11, "snowflake worker id lease lost")
+)
+var (
+	ErrCrossSlot = exception.New(-12, "keys in request don't hash to the same slot")
+)
+var (
+	ErrNotNumeric = exception.New(-13, "value is not numeric")
+)
+var (
+	ErrClientCacheUnsupported = exception.New(-14, "client side caching only supports alone connect type")
+)
+var (
+	ErrQueueCapacityNotSet = exception.New(-15, "bounded queue capacity is not set")
+)
+var (
+	ErrRingBufferCapacityNotSet = exception.New(-16, "ring buffer capacity is not set")
+	ErrInvalidCapacity          = exception.New(-18, "capacity must not be negative")
+)
+var (
+	ErrMalformedMember = exception.New(-17, "member is too short to carry its prefix")
+)
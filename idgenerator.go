@@ -0,0 +1,287 @@
+package redis
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+const (
+	IdGeneratorPrefix            = "idgenerator:"
+	DefaultIdGeneratorAllocation = 5000
+
+	// KEYS[1] 已分配的最大id KEYS[2] 每次分配的数量 ARGV[1] 初始值 ARGV[2] 每次分配的数量
+	IdGeneratorInitScript = "if redis.call('setnx', KEYS[1], ARGV[1] - 1) == 1 then redis.call('set', KEYS[2], ARGV[2]) return 1 end return 0"
+	// KEYS[1] 已分配的最大id KEYS[2] 每次分配的数量 ARGV[1] 未初始化时每次分配的数量
+	// 返回 {本次分配的起始id, 本次分配的结束id}
+	IdGeneratorAllocateScript = "local size = tonumber(redis.call('get', KEYS[2]) or ARGV[1]) local last = redis.call('incrby', KEYS[1], size) return {last - size + 1, last}"
+
+	SnowflakePrefix       = "snowflake:"
+	snowflakeWorkerBits   = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxWorker    = 1<<snowflakeWorkerBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+var (
+	idGeneratorInitScripter     = gredis.NewScript(IdGeneratorInitScript)
+	idGeneratorAllocateScripter = gredis.NewScript(IdGeneratorAllocateScript)
+
+	// SnowflakeEpoch 雪花id的时间起点
+	SnowflakeEpoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// IdGenerator 批量预分配的单调递增id生成器,本地号段用完一半时异步预取下一个号段
+type IdGenerator struct {
+	r              *Redis
+	name           string
+	allocationSize int64
+
+	mu        sync.Mutex
+	cur       int64 // 当前号段下一个可用id
+	end       int64 // 当前号段最后一个id
+	size      int64 // 当前号段的大小,由redis中保存的分配数量决定
+	nextStart int64
+	nextEnd   int64
+	hasNext   bool
+	refill    chan struct{} // 正在预取时不为nil,预取结束后关闭
+	refillErr error
+}
+
+// SnowflakeGenerator 雪花id生成器,worker id通过分布式锁租用,保证同一时刻不会重复
+type SnowflakeGenerator struct {
+	r        *Redis
+	lockName string
+	lockId   string
+	workerId int64
+	lease    time.Duration
+
+	mu      sync.Mutex
+	lastTs  int64
+	seq     int64
+	lost    bool
+	renewed time.Time // 最后一次续租成功时发出请求的时间,超过租期没有续租成功时不再生成id
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+/**
+ * 获取id生成器
+ *
+ * param: string name
+ * return: *IdGenerator
+ */
+func (r *Redis) GetIdGenerator(name string) *IdGenerator {
+	return &IdGenerator{r: r, name: r.namespaced(IdGeneratorPrefix + name), allocationSize: DefaultIdGeneratorAllocation, cur: 1}
+}
+
+/**
+ * 初始化起始值和每次分配的数量,已经初始化过时不会覆盖
+ *
+ * param: int64 value          起始id
+ * param: int64 allocationSize 每次从redis分配的数量
+ * return: bool
+ * return: error
+ */
+func (g *IdGenerator) TryInit(ctx context.Context, value, allocationSize int64) (bool, error) {
+	if allocationSize <= 0 {
+		return false, ErrInvalidPermits
+	}
+	res, err := idGeneratorInitScripter.Run(ctx, g.r, g.keys(), value, allocationSize).Int()
+	if err != nil {
+		return false, err
+	}
+	g.mu.Lock()
+	g.allocationSize = allocationSize
+	g.mu.Unlock()
+	return res == 1, nil
+}
+
+/**
+ * 获取下一个id
+ *
+ * return: int64
+ * return: error
+ */
+func (g *IdGenerator) NextId(ctx context.Context) (int64, error) {
+	g.mu.Lock()
+	for {
+		if g.cur <= g.end {
+			id := g.cur
+			g.cur++
+			if !g.hasNext && g.refill == nil && g.end-g.cur < g.size/2 {
+				g.startRefill()
+			}
+			g.mu.Unlock()
+			return id, nil
+		}
+		if g.hasNext {
+			g.cur, g.end, g.hasNext = g.nextStart, g.nextEnd, false
+			g.size = g.end - g.cur + 1
+			continue
+		}
+		if g.refill == nil {
+			if err := g.refillErr; err != nil {
+				g.refillErr = nil
+				g.mu.Unlock()
+				return 0, err
+			}
+			g.startRefill()
+		}
+		refill := g.refill
+		g.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-refill:
+		}
+		g.mu.Lock()
+	}
+}
+
+/**
+ * 异步预取下一个号段,调用时需持有g.mu
+ */
+func (g *IdGenerator) startRefill() {
+	done := make(chan struct{})
+	g.refill = done
+	size := g.allocationSize
+	go func() {
+		res, err := int64Slice(idGeneratorAllocateScripter.Run(context.Background(), g.r, g.keys(), size))
+		g.mu.Lock()
+		if err != nil {
+			g.refillErr = err
+		} else {
+			g.nextStart, g.nextEnd, g.hasNext = res[0], res[1], true
+		}
+		g.refill = nil
+		g.mu.Unlock()
+		close(done)
+	}()
+}
+
+func (g *IdGenerator) keys() []string {
+	return []string{g.name, suffixName(g.name, "allocation")}
+}
+
+/**
+ * 获取雪花id生成器,会租用一个空闲的worker id并定期续租
+ *
+ * param: string        name
+ * param: time.Duration leaseTime worker id租期,最小1秒
+ * return: *SnowflakeGenerator
+ * return: error
+ */
+func (r *Redis) GetSnowflakeGenerator(ctx context.Context, name string, leaseTime time.Duration) (*SnowflakeGenerator, error) {
+	lockTime := int64(leaseTime / time.Second)
+	if lockTime < 1 {
+		lockTime = 1
+	}
+	prefix := r.namespaced(SnowflakePrefix+name) + ":worker:"
+	offset := rand.Int63n(snowflakeMaxWorker + 1)
+	for i := int64(0); i <= snowflakeMaxWorker; i++ {
+		workerId := (offset + i) % (snowflakeMaxWorker + 1)
+		lockName := prefix + strconv.FormatInt(workerId, 10)
+		start := time.Now()
+		lockId, err := r.LockSingle(ctx, lockName, lockTime)
+		if err == ErrExitsLock {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		g := &SnowflakeGenerator{
+			r:        r,
+			lockName: lockName,
+			lockId:   lockId,
+			workerId: workerId,
+			lease:    time.Duration(lockTime) * time.Second,
+			renewed:  start,
+			stop:     make(chan struct{}),
+			done:     make(chan struct{}),
+		}
+		go g.renew(lockTime)
+		return g, nil
+	}
+	return nil, ErrNoWorkerId
+}
+
+/**
+ * 租用到的worker id
+ *
+ * return: int64
+ */
+func (g *SnowflakeGenerator) WorkerId() int64 {
+	return g.workerId
+}
+
+/**
+ * 获取下一个id,时钟回拨时沿用上一次的时间戳继续递增
+ *
+ * return: int64
+ * return: error worker id已被其他实例占用或超过租期没有续租成功时返回 ErrWorkerLeaseLost
+ */
+func (g *SnowflakeGenerator) NextId() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.lost || time.Since(g.renewed) >= g.lease {
+		return 0, ErrWorkerLeaseLost
+	}
+	ts := time.Since(SnowflakeEpoch).Milliseconds()
+	if ts <= g.lastTs {
+		ts = g.lastTs
+		g.seq = (g.seq + 1) & snowflakeMaxSequence
+		if g.seq == 0 {
+			ts++
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastTs = ts
+	return ts<<(snowflakeWorkerBits+snowflakeSequenceBits) | g.workerId<<snowflakeSequenceBits | g.seq, nil
+}
+
+/**
+ * 停止续租并释放worker id
+ *
+ * return: error
+ */
+func (g *SnowflakeGenerator) Close(ctx context.Context) error {
+	g.once.Do(func() {
+		close(g.stop)
+		<-g.done
+	})
+	return g.r.Unlock(ctx, g.lockName, g.lockId)
+}
+
+func (g *SnowflakeGenerator) renew(lockTime int64) {
+	defer close(g.done)
+	ticker := time.NewTicker(time.Duration(lockTime) * time.Second / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+			start := time.Now()
+			err := g.r.RenewLock(context.Background(), g.lockName, g.lockId, int(lockTime))
+			g.mu.Lock()
+			switch err {
+			case nil:
+				g.renewed = start
+			case gredis.Nil: // 锁已经过期或者被其他实例占用
+				g.lost = true
+			}
+			// 其他错误时锁可能已经过期,NextId会在超过租期后失败,继续重试续租
+			g.mu.Unlock()
+			if g.lost {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSnowflakeGenerator_NextId(t *testing.T) {
+	tests := []struct {
+		name    string
+		g       *SnowflakeGenerator
+		wantErr bool
+	}{
+		{"leased", &SnowflakeGenerator{workerId: 5, lease: time.Minute, renewed: time.Now()}, false},
+		{"lost", &SnowflakeGenerator{workerId: 5, lease: time.Minute, renewed: time.Now(), lost: true}, true},
+		{"lease-expired", &SnowflakeGenerator{workerId: 5, lease: time.Second, renewed: time.Now().Add(-time.Second)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var last int64
+			for i := 0; i < 10; i++ {
+				id, err := tt.g.NextId()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("NextId() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				if id <= last {
+					t.Fatalf("NextId() = %d, not greater than %d", id, last)
+				}
+				if worker := id >> snowflakeSequenceBits & snowflakeMaxWorker; worker != 5 {
+					t.Fatalf("NextId() worker = %d, want 5", worker)
+				}
+				last = id
+			}
+		})
+	}
+}
+
+func TestIdGenerator_NextId(t *testing.T) {
+	// 预取会失败,只使用已有的号段
+	g := &IdGenerator{r: unreachableRedis(), allocationSize: 100, cur: 1, end: 0, nextStart: 11, nextEnd: 14, hasNext: true}
+	ctx := context.Background()
+	for want := int64(11); want <= 14; want++ {
+		id, err := g.NextId(ctx)
+		if err != nil || id != want {
+			t.Fatalf("NextId() = %d, %v, want %d", id, err, want)
+		}
+		if g.size != 4 {
+			t.Fatalf("size = %d, want 4 from the allocated segment", g.size)
+		}
+	}
+	if _, err := g.NextId(ctx); err == nil {
+		t.Errorf("NextId() error = nil after segments are exhausted, want refill error")
+	}
+}
@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+)
+
+// scanIterator SCAN系列命令的游标遍历,遍历期间有修改时同一个元素可能返回多次
+type scanIterator struct {
+	scan   func(ctx context.Context, cursor uint64) ([]string, uint64, error)
+	cursor uint64
+	done   bool
+	buf    []string
+	err    error
+}
+
+/**
+ * 取出下一组元素,HSCAN和ZSCAN每个元素由两项组成
+ *
+ * param: int n 每组的项数
+ * return: []string 没有更多元素或出错时返回nil
+ */
+func (it *scanIterator) next(ctx context.Context, n int) []string {
+	for len(it.buf) < n {
+		if it.done || it.err != nil {
+			return nil
+		}
+		var items []string
+		items, it.cursor, it.err = it.scan(ctx, it.cursor)
+		if it.err != nil {
+			return nil
+		}
+		it.buf = append(it.buf, items...)
+		it.done = it.cursor == 0
+	}
+	items := it.buf[:n]
+	it.buf = it.buf[n:]
+	return items
+}
@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestScanIterator_next(t *testing.T) {
+	errScan := errors.New("scan failed")
+	tests := []struct {
+		name    string
+		pages   [][]string // 每次SCAN返回的元素,最后一页的游标为0
+		fail    bool       // 最后一页返回错误
+		n       int
+		want    [][]string
+		wantErr error
+	}{
+		{"empty", [][]string{{}}, false, 1, nil, nil},
+		{"single", [][]string{{"a", "b"}, {}, {"c"}}, false, 1, [][]string{{"a"}, {"b"}, {"c"}}, nil},
+		{"pairs", [][]string{{"k1", "v1", "k2"}, {"v2"}}, false, 2, [][]string{{"k1", "v1"}, {"k2", "v2"}}, nil},
+		{"error", [][]string{{"a"}, nil}, true, 1, [][]string{{"a"}}, errScan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := scanIterator{scan: func(ctx context.Context, cursor uint64) ([]string, uint64, error) {
+				if tt.fail && int(cursor) == len(tt.pages)-1 {
+					return nil, 0, errScan
+				}
+				next := cursor + 1
+				if int(next) == len(tt.pages) {
+					next = 0
+				}
+				return tt.pages[cursor], next, nil
+			}}
+			var got [][]string
+			for items := it.next(context.Background(), tt.n); items != nil; items = it.next(context.Background(), tt.n) {
+				got = append(got, items)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+			if it.err != tt.wantErr {
+				t.Errorf("err = %v, want %v", it.err, tt.wantErr)
+			}
+		})
+	}
+}
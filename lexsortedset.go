@@ -0,0 +1,315 @@
+package redis
+
+import (
+	"context"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+// LexSortedSet 所有元素分数都为0的有序集合,按字典序排序,元素保存原始字符串
+type LexSortedSet struct {
+	object
+}
+
+// LexSortedSetIterator 基于ZSCAN遍历LexSortedSet,遍历期间有修改时同一个元素可能返回多次
+type LexSortedSetIterator struct {
+	it *ScoredSortedSetIterator[string]
+}
+
+/**
+ * 获取LexSortedSet
+ *
+ * param: string name
+ * return: *LexSortedSet
+ */
+func (r *Redis) GetLexSortedSet(name string) *LexSortedSet {
+	return &LexSortedSet{object: r.newObject(name, codec.String)}
+}
+
+/**
+ * 添加元素
+ *
+ * param: ...string values
+ * return: int64 新增的数量
+ * return: error
+ */
+func (s *LexSortedSet) Add(ctx context.Context, values ...string) (int64, error) {
+	entries := make([]ScoredEntry[string], len(values))
+	for i, v := range values {
+		entries[i].Value = v
+	}
+	return s.sorted().AddAll(ctx, entries...)
+}
+
+/**
+ * 删除元素
+ *
+ * param: ...string values
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *LexSortedSet) Remove(ctx context.Context, values ...string) (int64, error) {
+	return s.sorted().Remove(ctx, values...)
+}
+
+/**
+ * 是否包含元素
+ *
+ * param: string value
+ * return: bool
+ * return: error
+ */
+func (s *LexSortedSet) Contains(ctx context.Context, value string) (bool, error) {
+	return s.sorted().Contains(ctx, value)
+}
+
+/**
+ * 元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (s *LexSortedSet) Size(ctx context.Context) (int64, error) {
+	return s.r.ZCard(ctx, s.name).Result()
+}
+
+/**
+ * 字典序区间内的元素
+ *
+ * param: string from
+ * param: bool   fromInclusive
+ * param: string to
+ * param: bool   toInclusive
+ * param: int64  offset
+ * param: int64  count 为0时不限制
+ * return: []string
+ * return: error
+ */
+func (s *LexSortedSet) Range(ctx context.Context, from string, fromInclusive bool, to string, toInclusive bool, offset, count int64) ([]string, error) {
+	by := lexRange(from, fromInclusive, to, toInclusive, offset, count)
+	return s.r.ZRangeByLex(ctx, s.name, by).Result()
+}
+
+/**
+ * 字典序区间内的元素,从大到小
+ *
+ * param: string from
+ * param: bool   fromInclusive
+ * param: string to
+ * param: bool   toInclusive
+ * param: int64  offset
+ * param: int64  count 为0时不限制
+ * return: []string
+ * return: error
+ */
+func (s *LexSortedSet) RangeReversed(ctx context.Context, from string, fromInclusive bool, to string, toInclusive bool, offset, count int64) ([]string, error) {
+	by := lexRange(from, fromInclusive, to, toInclusive, offset, count)
+	return s.r.ZRevRangeByLex(ctx, s.name, by).Result()
+}
+
+/**
+ * 小于(或等于)to的元素
+ *
+ * param: string to
+ * param: bool   toInclusive
+ * return: []string
+ * return: error
+ */
+func (s *LexSortedSet) RangeHead(ctx context.Context, to string, toInclusive bool) ([]string, error) {
+	by := lexRange("", true, to, toInclusive, 0, 0)
+	by.Min = "-"
+	return s.r.ZRangeByLex(ctx, s.name, by).Result()
+}
+
+/**
+ * 大于(或等于)from的元素
+ *
+ * param: string from
+ * param: bool   fromInclusive
+ * return: []string
+ * return: error
+ */
+func (s *LexSortedSet) RangeTail(ctx context.Context, from string, fromInclusive bool) ([]string, error) {
+	by := lexRange(from, fromInclusive, "", true, 0, 0)
+	by.Max = "+"
+	return s.r.ZRangeByLex(ctx, s.name, by).Result()
+}
+
+/**
+ * 字典序区间内的元素数量
+ *
+ * param: string from
+ * param: bool   fromInclusive
+ * param: string to
+ * param: bool   toInclusive
+ * return: int64
+ * return: error
+ */
+func (s *LexSortedSet) Count(ctx context.Context, from string, fromInclusive bool, to string, toInclusive bool) (int64, error) {
+	return s.r.ZLexCount(ctx, s.name, lexBound(from, fromInclusive), lexBound(to, toInclusive)).Result()
+}
+
+/**
+ * 删除字典序区间内的元素
+ *
+ * param: string from
+ * param: bool   fromInclusive
+ * param: string to
+ * param: bool   toInclusive
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *LexSortedSet) RemoveRange(ctx context.Context, from string, fromInclusive bool, to string, toInclusive bool) (int64, error) {
+	return s.r.ZRemRangeByLex(ctx, s.name, lexBound(from, fromInclusive), lexBound(to, toInclusive)).Result()
+}
+
+/**
+ * 字典序最小的元素
+ *
+ * return: string
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *LexSortedSet) First(ctx context.Context) (string, bool, error) {
+	entry, ok, err := s.sorted().First(ctx)
+	return entry.Value, ok, err
+}
+
+/**
+ * 字典序最大的元素
+ *
+ * return: string
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *LexSortedSet) Last(ctx context.Context) (string, bool, error) {
+	entry, ok, err := s.sorted().Last(ctx)
+	return entry.Value, ok, err
+}
+
+/**
+ * 删除并返回字典序最小的元素
+ *
+ * return: string
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *LexSortedSet) PollFirst(ctx context.Context) (string, bool, error) {
+	entry, ok, err := s.sorted().PollFirst(ctx)
+	return entry.Value, ok, err
+}
+
+/**
+ * 删除并返回字典序最大的元素
+ *
+ * return: string
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *LexSortedSet) PollLast(ctx context.Context) (string, bool, error) {
+	entry, ok, err := s.sorted().PollLast(ctx)
+	return entry.Value, ok, err
+}
+
+/**
+ * 把当前集合和names的并集保存到dest,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *LexSortedSet) Union(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	// 权重为0保证结果中的分数仍然都是0
+	return s.r.ZUnionStore(ctx, dest, &gredis.ZStore{Keys: keys, Weights: make([]float64, len(keys))}).Result()
+}
+
+/**
+ * 把当前集合和names的交集保存到dest,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *LexSortedSet) Intersect(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.ZInterStore(ctx, dest, &gredis.ZStore{Keys: keys, Weights: make([]float64, len(keys))}).Result()
+}
+
+/**
+ * 把当前集合与names的差集保存到dest,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *LexSortedSet) Diff(ctx context.Context, dest string, names ...string) (int64, error) {
+	return s.sorted().Diff(ctx, dest, names...)
+}
+
+/**
+ * 获取迭代器
+ *
+ * param: int64 count 每次ZSCAN的数量
+ * return: *LexSortedSetIterator
+ */
+func (s *LexSortedSet) Iterator(count int64) *LexSortedSetIterator {
+	return &LexSortedSetIterator{it: s.sorted().Iterator(count)}
+}
+
+func (s *LexSortedSet) sorted() *ScoredSortedSet[string] {
+	return &ScoredSortedSet[string]{object: s.object}
+}
+
+func lexBound(value string, inclusive bool) string {
+	if inclusive {
+		return "[" + value
+	}
+	return "(" + value
+}
+
+func lexRange(from string, fromInclusive bool, to string, toInclusive bool, offset, count int64) *gredis.ZRangeBy {
+	by := &gredis.ZRangeBy{Min: lexBound(from, fromInclusive), Max: lexBound(to, toInclusive), Offset: offset, Count: count}
+	if count == 0 && offset > 0 {
+		by.Count = -1
+	}
+	return by
+}
+
+/**
+ * 移动到下一个元素
+ *
+ * return: bool 是否还有元素
+ */
+func (it *LexSortedSetIterator) Next(ctx context.Context) bool {
+	return it.it.Next(ctx)
+}
+
+/**
+ * 当前元素
+ *
+ * return: string
+ */
+func (it *LexSortedSetIterator) Value() string {
+	return it.it.Value()
+}
+
+/**
+ * 遍历过程中的错误
+ *
+ * return: error
+ */
+func (it *LexSortedSetIterator) Err() error {
+	return it.it.Err()
+}
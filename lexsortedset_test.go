@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"reflect"
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+func TestLexRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		from          string
+		fromInclusive bool
+		to            string
+		toInclusive   bool
+		offset        int64
+		count         int64
+		want          gredis.ZRangeBy
+	}{
+		{"inclusive", "a", true, "c", true, 0, 0, gredis.ZRangeBy{Min: "[a", Max: "[c"}},
+		{"exclusive", "a", false, "c", false, 0, 0, gredis.ZRangeBy{Min: "(a", Max: "(c"}},
+		{"limit", "a", true, "c", false, 1, 2, gredis.ZRangeBy{Min: "[a", Max: "(c", Offset: 1, Count: 2}},
+		{"offset-only", "a", true, "c", true, 1, 0, gredis.ZRangeBy{Min: "[a", Max: "[c", Offset: 1, Count: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lexRange(tt.from, tt.fromInclusive, tt.to, tt.toInclusive, tt.offset, tt.count)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("lexRange() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
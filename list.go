@@ -0,0 +1,274 @@
+package redis
+
+import (
+	"context"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const DefaultListPageSize = 100
+
+// List 基于list的分布式列表,元素使用codec编码
+type List[V any] struct {
+	object
+}
+
+// ListIterator 按页读取列表,遍历期间有元素被删除时可能跳过部分元素,有元素插入时可能重复返回
+type ListIterator[V any] struct {
+	l        *List[V]
+	pageSize int64
+	index    int64
+	buf      []string
+	done     bool
+	value    V
+	err      error
+}
+
+/**
+ * 获取List
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *List[V]
+ */
+func GetList[V any](r *Redis, name string) *List[V] {
+	return GetListWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的List
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *List[V]
+ */
+func GetListWithCodec[V any](r *Redis, name string, c codec.Codec) *List[V] {
+	return &List[V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 在末尾添加元素
+ *
+ * param: ...V values
+ * return: int64 添加后的长度
+ * return: error
+ */
+func (l *List[V]) Add(ctx context.Context, values ...V) (int64, error) {
+	if len(values) == 0 {
+		return l.Size(ctx)
+	}
+	items, err := encodeValues(&l.object, values)
+	if err != nil {
+		return 0, err
+	}
+	return l.r.RPush(ctx, l.name, items...).Result()
+}
+
+/**
+ * 在第一个等于pivot的元素之前插入
+ *
+ * param: V pivot
+ * param: V value
+ * return: int64 插入后的长度,pivot不存在时返回-1
+ * return: error
+ */
+func (l *List[V]) InsertBefore(ctx context.Context, pivot, value V) (int64, error) {
+	return l.insert(ctx, "before", pivot, value)
+}
+
+/**
+ * 在第一个等于pivot的元素之后插入
+ *
+ * param: V pivot
+ * param: V value
+ * return: int64 插入后的长度,pivot不存在时返回-1
+ * return: error
+ */
+func (l *List[V]) InsertAfter(ctx context.Context, pivot, value V) (int64, error) {
+	return l.insert(ctx, "after", pivot, value)
+}
+
+/**
+ * 读取下标对应的元素,下标为负数时从末尾开始
+ *
+ * param: int64 index
+ * return: V
+ * return: bool 下标是否存在
+ * return: error
+ */
+func (l *List[V]) Get(ctx context.Context, index int64) (V, bool, error) {
+	return l.decodeResult(l.r.LIndex(ctx, l.name, index).Result())
+}
+
+/**
+ * 设置下标对应的元素,下标超出范围时返回错误
+ *
+ * param: int64 index
+ * param: V     value
+ * return: error
+ */
+func (l *List[V]) Set(ctx context.Context, index int64, value V) error {
+	item, err := l.encode(value)
+	if err != nil {
+		return err
+	}
+	return l.r.LSet(ctx, l.name, index, item).Err()
+}
+
+/**
+ * 第一个等于value的元素的下标
+ *
+ * param: V value
+ * return: int64
+ * return: bool 元素是否存在
+ * return: error
+ */
+func (l *List[V]) IndexOf(ctx context.Context, value V) (int64, bool, error) {
+	item, err := l.encode(value)
+	if err != nil {
+		return 0, false, err
+	}
+	return optionalResult(l.r.LPos(ctx, l.name, item, gredis.LPosArgs{}).Result())
+}
+
+/**
+ * 读取下标区间内的元素,包含start和end,下标为负数时从末尾开始
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: []V
+ * return: error
+ */
+func (l *List[V]) Range(ctx context.Context, start, end int64) ([]V, error) {
+	return decodeValues[V](l.codec)(l.r.LRange(ctx, l.name, start, end).Result())
+}
+
+/**
+ * 读取全部元素
+ *
+ * return: []V
+ * return: error
+ */
+func (l *List[V]) ReadAll(ctx context.Context) ([]V, error) {
+	return l.Range(ctx, 0, -1)
+}
+
+/**
+ * 删除等于value的元素
+ *
+ * param: V     value
+ * param: int64 count 大于0时从头开始删除count个,小于0时从末尾开始删除,等于0时全部删除
+ * return: int64 删除的数量
+ * return: error
+ */
+func (l *List[V]) Remove(ctx context.Context, value V, count int64) (int64, error) {
+	item, err := l.encode(value)
+	if err != nil {
+		return 0, err
+	}
+	return l.r.LRem(ctx, l.name, count, item).Result()
+}
+
+/**
+ * 只保留下标区间内的元素
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: error
+ */
+func (l *List[V]) Trim(ctx context.Context, start, end int64) error {
+	return l.r.LTrim(ctx, l.name, start, end).Err()
+}
+
+/**
+ * 元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (l *List[V]) Size(ctx context.Context) (int64, error) {
+	return l.r.LLen(ctx, l.name).Result()
+}
+
+/**
+ * 获取迭代器
+ *
+ * param: int64 pageSize 每次LRANGE读取的数量,小于等于0时使用 DefaultListPageSize
+ * return: *ListIterator[V]
+ */
+func (l *List[V]) Iterator(pageSize int64) *ListIterator[V] {
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+	return &ListIterator[V]{l: l, pageSize: pageSize}
+}
+
+func (l *List[V]) insert(ctx context.Context, op string, pivot, value V) (int64, error) {
+	p, err := l.encode(pivot)
+	if err != nil {
+		return 0, err
+	}
+	item, err := l.encode(value)
+	if err != nil {
+		return 0, err
+	}
+	return l.r.LInsert(ctx, l.name, op, p, item).Result()
+}
+
+func (l *List[V]) decodeResult(data string, err error) (V, bool, error) {
+	var v V
+	if err == gredis.Nil {
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	v, err = decodeAs[V](l.codec, data)
+	return v, err == nil, err
+}
+
+/**
+ * 移动到下一个元素
+ *
+ * return: bool 是否还有元素
+ */
+func (it *ListIterator[V]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		it.buf, it.err = it.l.r.LRange(ctx, it.l.name, it.index, it.index+it.pageSize-1).Result()
+		if it.err != nil || len(it.buf) == 0 {
+			return false
+		}
+		it.index += int64(len(it.buf))
+		it.done = int64(len(it.buf)) < it.pageSize
+	}
+	it.value, it.err = decodeAs[V](it.l.codec, it.buf[0])
+	it.buf = it.buf[1:]
+	return it.err == nil
+}
+
+/**
+ * 当前元素
+ *
+ * return: V
+ */
+func (it *ListIterator[V]) Value() V {
+	return it.value
+}
+
+/**
+ * 遍历过程中的错误
+ *
+ * return: error
+ */
+func (it *ListIterator[V]) Err() error {
+	return it.err
+}
@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+func TestList_decodeResult(t *testing.T) {
+	errGet := errors.New("get failed")
+	l := &List[int]{object: object{codec: codec.JSON}}
+	tests := []struct {
+		name    string
+		data    string
+		err     error
+		want    int
+		wantOk  bool
+		wantErr bool
+	}{
+		{"value", "3", nil, 3, true, false},
+		{"missing", "", gredis.Nil, 0, false, false},
+		{"error", "", errGet, 0, false, true},
+		{"undecodable", `"x"`, nil, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := l.decodeResult(tt.data, tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("decodeResult() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestList_Iterator_pageSize(t *testing.T) {
+	l := &List[int]{}
+	tests := []struct {
+		pageSize int64
+		want     int64
+	}{
+		{0, DefaultListPageSize},
+		{-1, DefaultListPageSize},
+		{5, 5},
+	}
+	for _, tt := range tests {
+		if got := l.Iterator(tt.pageSize).pageSize; got != tt.want {
+			t.Errorf("Iterator(%d).pageSize = %v, want %v", tt.pageSize, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// EvictionPolicy 本地缓存达到容量上限时的淘汰策略
+type EvictionPolicy int
+
+const (
+	EvictionPolicyNone EvictionPolicy = iota // 不淘汰,容量上限不生效
+	EvictionPolicyLRU                        // 淘汰最久未访问的entry
+	EvictionPolicyLFU                        // 淘汰访问次数最少的entry
+)
+
+// localCache 进程内缓存,支持容量、存活时间和空闲时间限制
+type localCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	policy  EvictionPolicy
+	size    int
+	ttl     time.Duration
+	maxIdle time.Duration
+	entries map[K]*list.Element
+	order   *list.List // 从最近访问到最久未访问
+	ver     uint64     // 每次写入、删除或清空时递增,用于丢弃过期的远程读取结果
+}
+
+type localCacheEntry[K comparable, V any] struct {
+	key      K
+	value    V
+	expireAt time.Time
+	accessAt time.Time
+	hits     int64
+}
+
+func newLocalCache[K comparable, V any](policy EvictionPolicy, size int, ttl, maxIdle time.Duration) *localCache[K, V] {
+	return &localCache[K, V]{
+		policy:  policy,
+		size:    size,
+		ttl:     ttl,
+		maxIdle: maxIdle,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *localCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var v V
+	elem, ok := c.entries[key]
+	if !ok {
+		return v, false
+	}
+	e := elem.Value.(*localCacheEntry[K, V])
+	now := time.Now()
+	if c.expired(e, now) {
+		c.removeElement(elem)
+		return v, false
+	}
+	e.accessAt = now
+	e.hits++
+	c.order.MoveToFront(elem)
+	return e.value, true
+}
+
+func (c *localCache[K, V]) put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ver++
+	c.set(key, value)
+}
+
+/**
+ * 读取期间没有发生过写入、删除和清空时才写入,避免读取到的旧值覆盖更新的值
+ *
+ * param: K      key
+ * param: V      value
+ * param: uint64 ver 读取前通过version获取的版本
+ */
+func (c *localCache[K, V]) putIfVersion(key K, value V, ver uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ver == ver {
+		c.set(key, value)
+	}
+}
+
+func (c *localCache[K, V]) remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ver++
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *localCache[K, V]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ver++
+	c.entries = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+func (c *localCache[K, V]) version() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ver
+}
+
+func (c *localCache[K, V]) keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]K, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (c *localCache[K, V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *localCache[K, V]) set(key K, value V) {
+	now := time.Now()
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*localCacheEntry[K, V])
+		e.value = value
+		e.expireAt = c.expireAt(now)
+		e.accessAt = now
+		c.order.MoveToFront(elem)
+		return
+	}
+	if c.policy != EvictionPolicyNone && c.size > 0 {
+		for len(c.entries) >= c.size {
+			c.evict()
+		}
+	}
+	e := &localCacheEntry[K, V]{key: key, value: value, expireAt: c.expireAt(now), accessAt: now}
+	c.entries[key] = c.order.PushFront(e)
+}
+
+func (c *localCache[K, V]) evict() {
+	victim := c.order.Back()
+	if c.policy == EvictionPolicyLFU {
+		// 访问次数相同时淘汰最久未访问的
+		for elem := c.order.Back(); elem != nil; elem = elem.Prev() {
+			if elem.Value.(*localCacheEntry[K, V]).hits < victim.Value.(*localCacheEntry[K, V]).hits {
+				victim = elem
+			}
+		}
+	}
+	c.removeElement(victim)
+}
+
+func (c *localCache[K, V]) removeElement(elem *list.Element) {
+	delete(c.entries, elem.Value.(*localCacheEntry[K, V]).key)
+	c.order.Remove(elem)
+}
+
+func (c *localCache[K, V]) expireAt(now time.Time) time.Time {
+	if c.ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(c.ttl)
+}
+
+func (c *localCache[K, V]) expired(e *localCacheEntry[K, V], now time.Time) bool {
+	if !e.expireAt.IsZero() && !now.Before(e.expireAt) {
+		return true
+	}
+	return c.maxIdle > 0 && now.Sub(e.accessAt) >= c.maxIdle
+}
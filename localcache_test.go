@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalCache_evict(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy EvictionPolicy
+		hits   []string // 写满后依次访问的key
+		want   []string // 写入d后仍然存在的key
+	}{
+		{"none", EvictionPolicyNone, nil, []string{"a", "b", "c", "d"}},
+		{"lru", EvictionPolicyLRU, []string{"a"}, []string{"a", "c", "d"}},
+		{"lfu", EvictionPolicyLFU, []string{"a", "a", "b", "c"}, []string{"a", "c", "d"}},
+		{"lfu-tie", EvictionPolicyLFU, []string{"b", "c"}, []string{"b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLocalCache[string, int](tt.policy, 3, 0, 0)
+			for i, key := range []string{"a", "b", "c"} {
+				c.put(key, i)
+			}
+			for _, key := range tt.hits {
+				c.get(key)
+			}
+			c.put("d", 3)
+			if c.len() != len(tt.want) {
+				t.Fatalf("len() = %d, want %d", c.len(), len(tt.want))
+			}
+			for _, key := range tt.want {
+				if _, ok := c.get(key); !ok {
+					t.Errorf("get(%q) missing", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalCache_expire(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		maxIdle time.Duration
+		touch   bool // 等待期间访问一次
+		want    bool
+	}{
+		{"no-limit", 0, 0, false, true},
+		{"ttl", 30 * time.Millisecond, 0, true, false},
+		{"max-idle", 0, 30 * time.Millisecond, false, false},
+		{"max-idle-touched", 0, 30 * time.Millisecond, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLocalCache[string, int](EvictionPolicyNone, 0, tt.ttl, tt.maxIdle)
+			c.put("a", 1)
+			time.Sleep(20 * time.Millisecond)
+			if tt.touch {
+				c.get("a")
+			}
+			time.Sleep(20 * time.Millisecond)
+			if _, ok := c.get("a"); ok != tt.want {
+				t.Errorf("get() ok = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalCache_putIfVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(c *localCache[string, int])
+		want  int
+		found bool
+	}{
+		{"no-write", func(c *localCache[string, int]) {}, 1, true},
+		{"put", func(c *localCache[string, int]) { c.put("a", 2) }, 2, true},
+		{"put-other-key", func(c *localCache[string, int]) { c.put("b", 2) }, 0, false},
+		{"remove", func(c *localCache[string, int]) { c.remove("a") }, 0, false},
+		{"clear", func(c *localCache[string, int]) { c.clear() }, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLocalCache[string, int](EvictionPolicyNone, 0, 0, 0)
+			ver := c.version()
+			tt.write(c) // 读取redis期间发生的写入
+			c.putIfVersion("a", 1, ver)
+			v, ok := c.get("a")
+			if ok != tt.found || v != tt.want {
+				t.Errorf("get() = %v, %v, want %v, %v", v, ok, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestLocalCache_concurrentMissAndUpdate(t *testing.T) {
+	c := newLocalCache[string, int](EvictionPolicyNone, 0, 0, 0)
+	read := make(chan struct{})
+	updated := make(chan struct{})
+	done := make(chan struct{})
+	go func() { // 本地缓存未命中,从redis读取到旧值后才写入本地缓存
+		defer close(done)
+		ver := c.version()
+		v := 1
+		close(read)
+		<-updated
+		c.putIfVersion("a", v, ver)
+	}()
+	<-read
+	c.put("a", 2) // 读取期间其他请求或更新通知写入了新值
+	close(updated)
+	<-done
+	if v, ok := c.get("a"); !ok || v != 2 {
+		t.Errorf("get() = %v, %v, want 2, true", v, ok)
+	}
+}
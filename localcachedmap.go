@@ -0,0 +1,520 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const localCachedMapLoadBatch = 100
+
+// SyncStrategy 修改map后通知其他实例的方式
+type SyncStrategy int
+
+const (
+	SyncStrategyInvalidate SyncStrategy = iota // 其他实例删除本地缓存中对应的entry
+	SyncStrategyUpdate                         // 其他实例用新值更新本地缓存
+	SyncStrategyNone                           // 不通知
+)
+
+// ReconnectStrategy 订阅连接断开重连后本地缓存的处理方式,断开期间的通知会丢失
+type ReconnectStrategy int
+
+const (
+	ReconnectStrategyNone  ReconnectStrategy = iota // 不处理
+	ReconnectStrategyClear                          // 清空本地缓存
+	ReconnectStrategyLoad                           // 从redis重新加载本地缓存中的entry
+)
+
+// LocalCachedMapOptions LocalCachedMap的配置
+type LocalCachedMapOptions struct {
+	EvictionPolicy    EvictionPolicy
+	CacheSize         int           // 本地缓存的最大entry数,0表示不限制
+	TimeToLive        time.Duration // 本地缓存entry的存活时间,0表示不过期
+	MaxIdle           time.Duration // 本地缓存entry的最大空闲时间,0表示不限制
+	SyncStrategy      SyncStrategy
+	ReconnectStrategy ReconnectStrategy
+	PingInterval      time.Duration // 订阅连接的检测间隔,0时使用 DefaultPubSubPingInterval
+}
+
+// LocalCachedMap 在Map前增加进程内缓存,修改时通过pub/sub通知其他实例失效或更新本地缓存
+type LocalCachedMap[K comparable, V any] struct {
+	object
+	hash   *Map[K, V]
+	cache  *localCache[K, V]
+	opts   LocalCachedMapOptions
+	id     string
+	topic  string
+	pubsub *gredis.PubSub
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+const (
+	localCacheMessageInvalidate = iota
+	localCacheMessageUpdate
+	localCacheMessageClear
+)
+
+// localCacheMessage 通知消息,key和value是codec编码后的内容
+type localCacheMessage struct {
+	Type   int      `json:"type"`
+	Origin string   `json:"origin"`
+	Keys   [][]byte `json:"keys,omitempty"`
+	Values [][]byte `json:"values,omitempty"`
+}
+
+/**
+ * 获取LocalCachedMap,返回前完成通知channel的订阅
+ *
+ * param: *Redis                r
+ * param: string                name
+ * param: LocalCachedMapOptions opts
+ * return: *LocalCachedMap[K, V]
+ * return: error
+ */
+func GetLocalCachedMap[K comparable, V any](ctx context.Context, r *Redis, name string, opts LocalCachedMapOptions) (*LocalCachedMap[K, V], error) {
+	return GetLocalCachedMapWithCodec[K, V](ctx, r, name, nil, opts)
+}
+
+/**
+ * 获取使用指定codec的LocalCachedMap,返回前完成通知channel的订阅
+ *
+ * param: *Redis                r
+ * param: string                name
+ * param: codec.Codec           c
+ * param: LocalCachedMapOptions opts
+ * return: *LocalCachedMap[K, V]
+ * return: error
+ */
+func GetLocalCachedMapWithCodec[K comparable, V any](ctx context.Context, r *Redis, name string, c codec.Codec, opts LocalCachedMapOptions) (*LocalCachedMap[K, V], error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	o := r.newObject(name, c)
+	m := &LocalCachedMap[K, V]{
+		object: o,
+		hash:   &Map[K, V]{object: o},
+		cache:  newLocalCache[K, V](opts.EvictionPolicy, opts.CacheSize, opts.TimeToLive, opts.MaxIdle),
+		opts:   opts,
+		id:     id,
+		topic:  suffixName(o.name, "topic"),
+		done:   make(chan struct{}),
+	}
+	m.pubsub = r.Subscribe(ctx, m.topic)
+	if _, err = m.pubsub.Receive(ctx); err != nil { // 等待订阅确认
+		_ = m.pubsub.Close()
+		return nil, err
+	}
+	var listenCtx context.Context
+	listenCtx, m.cancel = context.WithCancel(context.Background())
+	go m.listen(listenCtx)
+	return m, nil
+}
+
+/**
+ * 读取key对应的值,优先读取本地缓存
+ *
+ * param: K key
+ * return: V
+ * return: bool 是否存在
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	if v, ok := m.cache.get(key); ok {
+		return v, true, nil
+	}
+	ver := m.cache.version()
+	v, ok, err := m.hash.Get(ctx, key)
+	if err == nil && ok {
+		m.cache.putIfVersion(key, v, ver)
+	}
+	return v, ok, err
+}
+
+/**
+ * key是否存在,优先读取本地缓存
+ *
+ * param: K key
+ * return: bool
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) ContainsKey(ctx context.Context, key K) (bool, error) {
+	_, ok, err := m.Get(ctx, key)
+	return ok, err
+}
+
+/**
+ * 设置值并返回旧值
+ *
+ * param: K key
+ * param: V value
+ * return: V    旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) Put(ctx context.Context, key K, value V) (V, bool, error) {
+	old, loaded, err := m.hash.Put(ctx, key, value)
+	if err != nil {
+		return old, loaded, err
+	}
+	return old, loaded, m.updated(ctx, key, value)
+}
+
+/**
+ * 设置值,不返回旧值
+ *
+ * param: K key
+ * param: V value
+ * return: bool 是否是新增的key
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) FastPut(ctx context.Context, key K, value V) (bool, error) {
+	added, err := m.hash.FastPut(ctx, key, value)
+	if err != nil {
+		return added, err
+	}
+	return added, m.updated(ctx, key, value)
+}
+
+/**
+ * key不存在时设置值
+ *
+ * param: K key
+ * param: V value
+ * return: V    已经存在的值
+ * return: bool 是否已经存在,存在时不会设置
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) PutIfAbsent(ctx context.Context, key K, value V) (V, bool, error) {
+	old, loaded, err := m.hash.PutIfAbsent(ctx, key, value)
+	if err != nil {
+		return old, loaded, err
+	}
+	if loaded {
+		m.cache.put(key, old)
+		return old, loaded, nil
+	}
+	return old, loaded, m.updated(ctx, key, value)
+}
+
+/**
+ * key不存在时设置值,不返回旧值
+ *
+ * param: K key
+ * param: V value
+ * return: bool 是否设置成功
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) FastPutIfAbsent(ctx context.Context, key K, value V) (bool, error) {
+	ok, err := m.hash.FastPutIfAbsent(ctx, key, value)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return ok, m.updated(ctx, key, value)
+}
+
+/**
+ * key存在时替换值
+ *
+ * param: K key
+ * param: V value
+ * return: V    旧值
+ * return: bool 是否存在,不存在时不会设置
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	old, loaded, err := m.hash.Replace(ctx, key, value)
+	if err != nil || !loaded {
+		return old, loaded, err
+	}
+	return old, loaded, m.updated(ctx, key, value)
+}
+
+/**
+ * 对数值类型的值加delta并返回新值
+ *
+ * param: K key
+ * param: V delta
+ * return: V
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) AddAndGet(ctx context.Context, key K, delta V) (V, error) {
+	v, err := m.hash.AddAndGet(ctx, key, delta)
+	if err != nil {
+		return v, err
+	}
+	return v, m.updated(ctx, key, v)
+}
+
+/**
+ * 删除key并返回旧值
+ *
+ * param: K key
+ * return: V    旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) Remove(ctx context.Context, key K) (V, bool, error) {
+	old, loaded, err := m.hash.Remove(ctx, key)
+	if err != nil {
+		return old, loaded, err
+	}
+	return old, loaded, m.removed(ctx, key)
+}
+
+/**
+ * 批量删除key,不返回旧值
+ *
+ * param: ...K keys
+ * return: int64 删除的数量
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) FastRemove(ctx context.Context, keys ...K) (int64, error) {
+	n, err := m.hash.FastRemove(ctx, keys...)
+	if err != nil {
+		return n, err
+	}
+	return n, m.removed(ctx, keys...)
+}
+
+/**
+ * 读取全部内容,直接读取redis
+ *
+ * return: map[K]V
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) ReadAllMap(ctx context.Context) (map[K]V, error) {
+	return m.hash.ReadAllMap(ctx)
+}
+
+/**
+ * key的数量,直接读取redis
+ *
+ * return: int64
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) Size(ctx context.Context) (int64, error) {
+	return m.hash.Size(ctx)
+}
+
+/**
+ * 获取迭代器,直接读取redis
+ *
+ * param: int64 count 每次HSCAN的数量
+ * return: *MapIterator[K, V]
+ */
+func (m *LocalCachedMap[K, V]) Iterator(count int64) *MapIterator[K, V] {
+	return m.hash.Iterator(count)
+}
+
+/**
+ * 删除对象并清空所有实例的本地缓存
+ *
+ * return: bool 删除前是否存在
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) Delete(ctx context.Context) (bool, error) {
+	ok, err := m.object.Delete(ctx)
+	if err != nil {
+		return ok, err
+	}
+	return ok, m.ClearLocalCache(ctx)
+}
+
+/**
+ * 把redis中的全部内容加载到本地缓存
+ *
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) PreloadCache(ctx context.Context) error {
+	ver := m.cache.version()
+	entries, err := m.hash.ReadAllMap(ctx)
+	if err != nil {
+		return err
+	}
+	for key, value := range entries {
+		m.cache.putIfVersion(key, value, ver)
+	}
+	return nil
+}
+
+/**
+ * 清空所有实例的本地缓存
+ *
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) ClearLocalCache(ctx context.Context) error {
+	m.cache.clear()
+	return m.publish(ctx, &localCacheMessage{Type: localCacheMessageClear})
+}
+
+/**
+ * 本地缓存的entry数
+ *
+ * return: int
+ */
+func (m *LocalCachedMap[K, V]) CachedSize() int {
+	return m.cache.len()
+}
+
+/**
+ * 取消订阅并停止接收通知,可以重复调用
+ *
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) Close() error {
+	var err error
+	m.once.Do(func() {
+		m.cancel()
+		err = m.pubsub.Close()
+		<-m.done
+	})
+	return err
+}
+
+func (m *LocalCachedMap[K, V]) updated(ctx context.Context, key K, value V) error {
+	m.cache.put(key, value)
+	switch m.opts.SyncStrategy {
+	case SyncStrategyInvalidate:
+		return m.notify(ctx, localCacheMessageInvalidate, []K{key}, nil)
+	case SyncStrategyUpdate:
+		return m.notify(ctx, localCacheMessageUpdate, []K{key}, []V{value})
+	}
+	return nil
+}
+
+func (m *LocalCachedMap[K, V]) removed(ctx context.Context, keys ...K) error {
+	for _, key := range keys {
+		m.cache.remove(key)
+	}
+	if m.opts.SyncStrategy == SyncStrategyNone || len(keys) == 0 {
+		return nil
+	}
+	return m.notify(ctx, localCacheMessageInvalidate, keys, nil)
+}
+
+func (m *LocalCachedMap[K, V]) notify(ctx context.Context, typ int, keys []K, values []V) error {
+	msg := &localCacheMessage{Type: typ, Keys: make([][]byte, len(keys))}
+	var err error
+	for i, key := range keys {
+		if msg.Keys[i], err = m.codec.Encode(key); err != nil {
+			return err
+		}
+	}
+	for _, value := range values {
+		data, err := m.codec.Encode(value)
+		if err != nil {
+			return err
+		}
+		msg.Values = append(msg.Values, data)
+	}
+	return m.publish(ctx, msg)
+}
+
+func (m *LocalCachedMap[K, V]) publish(ctx context.Context, msg *localCacheMessage) error {
+	msg.Origin = m.id
+	data, err := codec.JSON.Encode(msg)
+	if err != nil {
+		return err
+	}
+	return m.r.Publish(ctx, m.topic, data).Err()
+}
+
+func (m *LocalCachedMap[K, V]) listen(ctx context.Context) {
+	defer close(m.done)
+	listenPubSub(ctx, m.pubsub, m.opts.PingInterval, pubSubHandler{
+		onMessage: func(msg *gredis.Message) {
+			m.handle(msg.Payload)
+		},
+		onReconnect: func() {
+			m.reconnected(ctx)
+		},
+	})
+}
+
+func (m *LocalCachedMap[K, V]) handle(payload string) {
+	var msg localCacheMessage
+	if err := codec.JSON.Decode([]byte(payload), &msg); err != nil || msg.Origin == m.id {
+		return
+	}
+	if msg.Type == localCacheMessageClear {
+		m.cache.clear()
+		return
+	}
+	for i, data := range msg.Keys {
+		var key K
+		if err := m.codec.Decode(data, &key); err != nil {
+			continue
+		}
+		if msg.Type == localCacheMessageUpdate && i < len(msg.Values) {
+			var value V
+			if err := m.codec.Decode(msg.Values[i], &value); err == nil {
+				m.cache.put(key, value)
+				continue
+			}
+		}
+		m.cache.remove(key)
+	}
+}
+
+func (m *LocalCachedMap[K, V]) reconnected(ctx context.Context) {
+	switch m.opts.ReconnectStrategy {
+	case ReconnectStrategyClear:
+		m.cache.clear()
+	case ReconnectStrategyLoad:
+		if err := m.reload(ctx); err != nil {
+			m.cache.clear()
+		}
+	}
+}
+
+/**
+ * 从redis重新读取本地缓存中已有的entry,redis中已经不存在的从本地缓存删除
+ *
+ * return: error
+ */
+func (m *LocalCachedMap[K, V]) reload(ctx context.Context) error {
+	keys := m.cache.keys()
+	for start := 0; start < len(keys); start += localCachedMapLoadBatch {
+		end := start + localCachedMapLoadBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batch := keys[start:end]
+		fields := make([]string, len(batch))
+		for i, key := range batch {
+			field, err := m.encode(key)
+			if err != nil {
+				return err
+			}
+			fields[i] = field
+		}
+		ver := m.cache.version()
+		values, err := m.r.HMGet(ctx, m.name, fields...).Result()
+		if err != nil {
+			return err
+		}
+		for i, v := range values {
+			data, ok := v.(string)
+			if !ok {
+				m.cache.remove(batch[i])
+				ver = m.cache.version()
+				continue
+			}
+			value, err := decodeAs[V](m.codec, data)
+			if err != nil {
+				return err
+			}
+			m.cache.putIfVersion(batch[i], value, ver)
+		}
+	}
+	return nil
+}
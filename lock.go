@@ -16,9 +16,9 @@ const (
 )
 
 var (
-	lockScripter           *gredis.Script
-	unlockScripter         *gredis.Script
-	renewScripter          *gredis.Script
+	lockScripter   = gredis.NewScript(LockScript)
+	unlockScripter = gredis.NewScript(UnlockScript)
+	renewScripter  = gredis.NewScript(RenewLockScript)
 )
 
 /**
@@ -146,9 +146,6 @@ func (r *Redis) RenewLock(ctx context.Context, lockName, lockId string, renameTi
 }
 
 func (r *Redis) LoadLockScript(ctx context.Context) (*gredis.Script, error) {
-	if lockScripter == nil {
-		lockScripter = gredis.NewScript(LockScript)
-	}
 	_, err := lockScripter.Load(ctx, r).Result()
 	if err != nil {
 		return nil, err
@@ -158,9 +155,6 @@ func (r *Redis) LoadLockScript(ctx context.Context) (*gredis.Script, error) {
 }
 
 func (r *Redis) LoadUnLockScript(ctx context.Context) (*gredis.Script, error) {
-	if unlockScripter == nil {
-		unlockScripter = gredis.NewScript(UnlockScript)
-	}
 	_, err := unlockScripter.Load(ctx, r).Result()
 	if err != nil {
 		return nil, err
@@ -169,10 +163,7 @@ func (r *Redis) LoadUnLockScript(ctx context.Context) (*gredis.Script, error) {
 }
 
 func (r *Redis) LoadRenewScript(ctx context.Context) (*gredis.Script, error) {
-	if renewScripter == nil {
-		renewScripter = gredis.NewScript(RenewLockScript)
-	}
-	_, err := unlockScripter.Load(ctx, r).Result()
+	_, err := renewScripter.Load(ctx, r).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -180,34 +171,13 @@ func (r *Redis) LoadRenewScript(ctx context.Context) (*gredis.Script, error) {
 }
 
 func (r *Redis) GetLockScripter(ctx context.Context) *gredis.Script {
-	var err error
-	if lockScripter == nil {
-		lockScripter, err = r.LoadLockScript(ctx)
-		if err != nil {
-			panic(err)
-		}
-	}
 	return lockScripter
 }
 
 func (r *Redis) GetUnlockScripter(ctx context.Context) *gredis.Script {
-	var err error
-	if unlockScripter == nil {
-		unlockScripter, err = r.LoadUnLockScript(ctx)
-		if err != nil {
-			panic(err)
-		}
-	}
 	return unlockScripter
 }
 
 func (r *Redis) GetRenewScripter(ctx context.Context) *gredis.Script {
-	var err error
-	if renewScripter == nil {
-		renewScripter, err = r.LoadRenewScript(ctx)
-		if err != nil {
-			panic(err)
-		}
-	}
 	return renewScripter
 }
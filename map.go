@@ -0,0 +1,450 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	MapPutScript         = "local v = redis.call('hget', KEYS[1], ARGV[1]) redis.call('hset', KEYS[1], ARGV[1], ARGV[2]) return v"
+	MapPutIfAbsentScript = "local v = redis.call('hget', KEYS[1], ARGV[1]) if v == false then redis.call('hset', KEYS[1], ARGV[1], ARGV[2]) end return v"
+	MapReplaceScript     = "local v = redis.call('hget', KEYS[1], ARGV[1]) if v ~= false then redis.call('hset', KEYS[1], ARGV[1], ARGV[2]) end return v"
+	MapRemoveScript      = "local v = redis.call('hget', KEYS[1], ARGV[1]) if v ~= false then redis.call('hdel', KEYS[1], ARGV[1]) end return v"
+)
+
+var (
+	mapPutScripter         = gredis.NewScript(MapPutScript)
+	mapPutIfAbsentScripter = gredis.NewScript(MapPutIfAbsentScript)
+	mapReplaceScripter     = gredis.NewScript(MapReplaceScript)
+	mapRemoveScripter      = gredis.NewScript(MapRemoveScript)
+)
+
+// Map 基于hash的分布式map,key和value都使用codec编码
+type Map[K comparable, V any] struct {
+	object
+	store *mapStore[K, V]
+}
+
+// MapIterator 基于HSCAN遍历map,遍历期间有修改时同一个key可能返回多次
+type MapIterator[K comparable, V any] struct {
+	m     *Map[K, V]
+	scan  scanIterator
+	key   K
+	value V
+	err   error
+}
+
+/**
+ * 获取Map
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *Map[K, V]
+ */
+func GetMap[K comparable, V any](r *Redis, name string) *Map[K, V] {
+	return GetMapWithCodec[K, V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的Map
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *Map[K, V]
+ */
+func GetMapWithCodec[K comparable, V any](r *Redis, name string, c codec.Codec) *Map[K, V] {
+	return &Map[K, V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 获取关联外部存储的Map,读取不存在的key时通过Loader加载,修改后通过Writer写入,使用完后需要调用Close
+ *
+ * param: *Redis           r
+ * param: string           name
+ * param: codec.Codec      c    为nil时使用客户端默认的codec
+ * param: MapOptions[K, V] opts
+ * return: *Map[K, V]
+ */
+func GetMapWithOptions[K comparable, V any](r *Redis, name string, c codec.Codec, opts MapOptions[K, V]) *Map[K, V] {
+	return &Map[K, V]{object: r.newObject(name, c), store: newMapStore(opts)}
+}
+
+/**
+ * 读取key对应的值,配置了Loader时从外部存储加载不存在的key
+ *
+ * param: K key
+ * return: V
+ * return: bool 是否存在
+ * return: error
+ */
+func (m *Map[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	v, ok, err := m.get(ctx, key)
+	loader := m.store.loader()
+	if err != nil || ok || loader == nil {
+		return v, ok, err
+	}
+	if v, ok, err = loader.Load(ctx, key); err != nil || !ok {
+		return v, false, err
+	}
+	field, data, err := m.encodeEntry(key, v)
+	if err != nil {
+		return v, false, err
+	}
+	// 加载期间其他客户端已经写入时以redis中的值为准
+	if set, err := m.r.HSetNX(ctx, m.name, field, data).Result(); err != nil || set {
+		return v, err == nil, err
+	}
+	return m.get(ctx, key)
+}
+
+func (m *Map[K, V]) get(ctx context.Context, key K) (V, bool, error) {
+	var v V
+	field, err := m.encode(key)
+	if err != nil {
+		return v, false, err
+	}
+	data, err := m.r.HGet(ctx, m.name, field).Result()
+	if err == gredis.Nil {
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	v, err = decodeAs[V](m.codec, data)
+	return v, err == nil, err
+}
+
+/**
+ * 设置值并返回旧值
+ *
+ * param: K key
+ * param: V value
+ * return: V    旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (m *Map[K, V]) Put(ctx context.Context, key K, value V) (V, bool, error) {
+	old, loaded, err := m.run(ctx, mapPutScripter, key, value)
+	if err != nil {
+		return old, loaded, err
+	}
+	return old, loaded, m.store.write(ctx, key, value)
+}
+
+/**
+ * key不存在时设置值
+ *
+ * param: K key
+ * param: V value
+ * return: V    已经存在的值
+ * return: bool 是否已经存在,存在时不会设置
+ * return: error
+ */
+func (m *Map[K, V]) PutIfAbsent(ctx context.Context, key K, value V) (V, bool, error) {
+	old, loaded, err := m.run(ctx, mapPutIfAbsentScripter, key, value)
+	if err != nil || loaded {
+		return old, loaded, err
+	}
+	return old, loaded, m.store.write(ctx, key, value)
+}
+
+/**
+ * key存在时替换值
+ *
+ * param: K key
+ * param: V value
+ * return: V    旧值
+ * return: bool 是否存在,不存在时不会设置
+ * return: error
+ */
+func (m *Map[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+	old, loaded, err := m.run(ctx, mapReplaceScripter, key, value)
+	if err != nil || !loaded {
+		return old, loaded, err
+	}
+	return old, loaded, m.store.write(ctx, key, value)
+}
+
+/**
+ * 删除key并返回旧值,配置了Writer时同时从外部存储删除
+ *
+ * param: K key
+ * return: V    旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (m *Map[K, V]) Remove(ctx context.Context, key K) (V, bool, error) {
+	var v V
+	field, err := m.encode(key)
+	if err != nil {
+		return v, false, err
+	}
+	old, loaded, err := decodeCmd[V](m.codec, mapRemoveScripter.Run(ctx, m.r, []string{m.name}, field))
+	if err != nil {
+		return old, loaded, err
+	}
+	return old, loaded, m.store.delete(ctx, key)
+}
+
+/**
+ * 设置值,不返回旧值
+ *
+ * param: K key
+ * param: V value
+ * return: bool 是否是新增的key
+ * return: error
+ */
+func (m *Map[K, V]) FastPut(ctx context.Context, key K, value V) (bool, error) {
+	field, data, err := m.encodeEntry(key, value)
+	if err != nil {
+		return false, err
+	}
+	n, err := m.r.HSet(ctx, m.name, field, data).Result()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, m.store.write(ctx, key, value)
+}
+
+/**
+ * key不存在时设置值,不返回旧值
+ *
+ * param: K key
+ * param: V value
+ * return: bool 是否设置成功
+ * return: error
+ */
+func (m *Map[K, V]) FastPutIfAbsent(ctx context.Context, key K, value V) (bool, error) {
+	field, data, err := m.encodeEntry(key, value)
+	if err != nil {
+		return false, err
+	}
+	ok, err := m.r.HSetNX(ctx, m.name, field, data).Result()
+	if err != nil || !ok {
+		return ok, err
+	}
+	return ok, m.store.write(ctx, key, value)
+}
+
+/**
+ * 批量删除key,不返回旧值,配置了Writer时同时从外部存储删除
+ *
+ * param: ...K keys
+ * return: int64 删除的数量
+ * return: error
+ */
+func (m *Map[K, V]) FastRemove(ctx context.Context, keys ...K) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	fields := make([]string, len(keys))
+	for i, key := range keys {
+		field, err := m.encode(key)
+		if err != nil {
+			return 0, err
+		}
+		fields[i] = field
+	}
+	n, err := m.r.HDel(ctx, m.name, fields...).Result()
+	if err != nil {
+		return n, err
+	}
+	return n, m.store.delete(ctx, keys...)
+}
+
+/**
+ * key是否存在,配置了Loader时会从外部存储加载不存在的key
+ *
+ * param: K key
+ * return: bool
+ * return: error
+ */
+func (m *Map[K, V]) ContainsKey(ctx context.Context, key K) (bool, error) {
+	if m.store.loader() != nil {
+		_, ok, err := m.Get(ctx, key)
+		return ok, err
+	}
+	field, err := m.encode(key)
+	if err != nil {
+		return false, err
+	}
+	return m.r.HExists(ctx, m.name, field).Result()
+}
+
+/**
+ * 读取全部内容
+ *
+ * return: map[K]V
+ * return: error
+ */
+func (m *Map[K, V]) ReadAllMap(ctx context.Context) (map[K]V, error) {
+	entries, err := m.r.HGetAll(ctx, m.name).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[K]V, len(entries))
+	for field, data := range entries {
+		key, value, err := m.decodeEntry(field, data)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = value
+	}
+	return res, nil
+}
+
+/**
+ * key的数量
+ *
+ * return: int64
+ * return: error
+ */
+func (m *Map[K, V]) Size(ctx context.Context) (int64, error) {
+	return m.r.HLen(ctx, m.name).Result()
+}
+
+/**
+ * 对数值类型的值加delta并返回新值,编码后的值必须是数字字符串(如json codec)
+ *
+ * param: K key
+ * param: V delta
+ * return: V
+ * return: error
+ */
+func (m *Map[K, V]) AddAndGet(ctx context.Context, key K, delta V) (V, error) {
+	var v V
+	field, data, err := m.encodeEntry(key, delta)
+	if err != nil {
+		return v, err
+	}
+	var res string
+	if n, err := strconv.ParseInt(data, 10, 64); err == nil && !isFloatType[V]() {
+		var i int64
+		if i, err = m.r.HIncrBy(ctx, m.name, field, n).Result(); err != nil {
+			return v, err
+		}
+		res = strconv.FormatInt(i, 10)
+	} else if f, err := strconv.ParseFloat(data, 64); err == nil {
+		if f, err = m.r.HIncrByFloat(ctx, m.name, field, f).Result(); err != nil {
+			return v, err
+		}
+		res = formatFloat(f)
+	} else {
+		return v, ErrNotNumeric
+	}
+	if v, err = decodeAs[V](m.codec, res); err != nil {
+		return v, err
+	}
+	return v, m.store.write(ctx, key, v)
+}
+
+/**
+ * 获取迭代器
+ *
+ * param: int64 count 每次HSCAN的数量
+ * return: *MapIterator[K, V]
+ */
+func (m *Map[K, V]) Iterator(count int64) *MapIterator[K, V] {
+	it := &MapIterator[K, V]{m: m}
+	it.scan.scan = func(ctx context.Context, cursor uint64) ([]string, uint64, error) {
+		return m.r.HScan(ctx, m.name, cursor, "", count).Result()
+	}
+	return it
+}
+
+/**
+ * 停止异步写入并把剩余的修改写入外部存储,可以重复调用
+ */
+func (m *Map[K, V]) Close() {
+	m.store.close()
+}
+
+func isFloatType[V any]() bool {
+	switch reflect.TypeOf((*V)(nil)).Elem().Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func (m *Map[K, V]) run(ctx context.Context, script *gredis.Script, key K, value V) (V, bool, error) {
+	field, data, err := m.encodeEntry(key, value)
+	if err != nil {
+		var v V
+		return v, false, err
+	}
+	return decodeCmd[V](m.codec, script.Run(ctx, m.r, []string{m.name}, field, data))
+}
+
+func (m *Map[K, V]) encodeEntry(key K, value V) (string, string, error) {
+	field, err := m.encode(key)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := m.encode(value)
+	if err != nil {
+		return "", "", err
+	}
+	return field, data, nil
+}
+
+func (m *Map[K, V]) decodeEntry(field, data string) (K, V, error) {
+	var v V
+	key, err := decodeAs[K](m.codec, field)
+	if err != nil {
+		return key, v, err
+	}
+	v, err = decodeAs[V](m.codec, data)
+	return key, v, err
+}
+
+/**
+ * 移动到下一个元素
+ *
+ * return: bool 是否还有元素
+ */
+func (it *MapIterator[K, V]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	entry := it.scan.next(ctx, 2)
+	if entry == nil {
+		it.err = it.scan.err
+		return false
+	}
+	it.key, it.value, it.err = it.m.decodeEntry(entry[0], entry[1])
+	return it.err == nil
+}
+
+/**
+ * 当前元素的key
+ *
+ * return: K
+ */
+func (it *MapIterator[K, V]) Key() K {
+	return it.key
+}
+
+/**
+ * 当前元素的值
+ *
+ * return: V
+ */
+func (it *MapIterator[K, V]) Value() V {
+	return it.value
+}
+
+/**
+ * 遍历过程中的错误
+ *
+ * return: error
+ */
+func (it *MapIterator[K, V]) Err() error {
+	return it.err
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+func TestIsFloatType(t *testing.T) {
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"float32", isFloatType[float32](), true},
+		{"float64", isFloatType[float64](), true},
+		{"int64", isFloatType[int64](), false},
+		{"string", isFloatType[string](), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("isFloatType() = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMap_entry(t *testing.T) {
+	m := &Map[string, int]{object: object{codec: codec.JSON}}
+	tests := []struct {
+		name      string
+		field     string
+		data      string
+		wantKey   string
+		wantValue int
+		wantErr   bool
+	}{
+		{"entry", `"a"`, "1", "a", 1, false},
+		{"bad key", `a`, "1", "", 0, true},
+		{"bad value", `"a"`, `"x"`, "a", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, err := m.decodeEntry(tt.field, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("decodeEntry() = %v, %v, want %v, %v", key, value, tt.wantKey, tt.wantValue)
+			}
+			field, data, err := m.encodeEntry(key, value)
+			if err != nil || field != tt.field || data != tt.data {
+				t.Errorf("encodeEntry() = %v, %v, %v, want %v, %v", field, data, err, tt.field, tt.data)
+			}
+		})
+	}
+}
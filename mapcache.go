@@ -0,0 +1,398 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	MapCacheEvictionBatch = 100
+
+	// KEYS[1] 数据hash KEYS[2] 过期时间有序集合 KEYS[3] 空闲过期时间有序集合 KEYS[4] 最大空闲时间hash
+	mapCacheScriptHeader = serverNowScript + `
+local function expired(field)
+	local ttl = redis.call('zscore', KEYS[2], field)
+	if ttl ~= false and tonumber(ttl) <= now then
+		return true
+	end
+	local idle = redis.call('zscore', KEYS[3], field)
+	return idle ~= false and tonumber(idle) <= now
+end
+local function touch(field)
+	local maxIdle = redis.call('hget', KEYS[4], field)
+	if maxIdle ~= false then
+		redis.call('zadd', KEYS[3], now + tonumber(maxIdle), field)
+	end
+end
+local function put(field, value, ttl, maxIdle)
+	redis.call('hset', KEYS[1], field, value)
+	if tonumber(ttl) > 0 then
+		redis.call('zadd', KEYS[2], now + tonumber(ttl), field)
+	else
+		redis.call('zrem', KEYS[2], field)
+	end
+	if tonumber(maxIdle) > 0 then
+		redis.call('zadd', KEYS[3], now + tonumber(maxIdle), field)
+		redis.call('hset', KEYS[4], field, maxIdle)
+	else
+		redis.call('zrem', KEYS[3], field)
+		redis.call('hdel', KEYS[4], field)
+	end
+end
+local function remove(field)
+	redis.call('hdel', KEYS[1], field)
+	redis.call('zrem', KEYS[2], field)
+	redis.call('zrem', KEYS[3], field)
+	redis.call('hdel', KEYS[4], field)
+end
+local function expiredFields()
+	local fields = {}
+	for _, key in ipairs({KEYS[2], KEYS[3]}) do
+		for _, field in ipairs(redis.call('zrangebyscore', key, '-inf', now)) do
+			fields[field] = true
+		end
+	end
+	return fields
+end
+`
+	// ARGV[1] key
+	MapCacheGetScript = mapCacheScriptHeader + `
+local v = redis.call('hget', KEYS[1], ARGV[1])
+if v == false or expired(ARGV[1]) then
+	return nil
+end
+touch(ARGV[1])
+return v
+`
+	// ARGV[1] key ARGV[2] value ARGV[3] ttl(毫秒) ARGV[4] 最大空闲时间(毫秒)
+	MapCachePutScript = mapCacheScriptHeader + `
+local v = redis.call('hget', KEYS[1], ARGV[1])
+if v ~= false and expired(ARGV[1]) then
+	v = false
+end
+put(ARGV[1], ARGV[2], ARGV[3], ARGV[4])
+return v
+`
+	MapCachePutIfAbsentScript = mapCacheScriptHeader + `
+local v = redis.call('hget', KEYS[1], ARGV[1])
+if v ~= false and not expired(ARGV[1]) then
+	touch(ARGV[1])
+	return v
+end
+put(ARGV[1], ARGV[2], ARGV[3], ARGV[4])
+return nil
+`
+	// ARGV[1] key
+	MapCacheRemoveScript = mapCacheScriptHeader + `
+local v = redis.call('hget', KEYS[1], ARGV[1])
+if v == false then
+	return nil
+end
+local isExpired = expired(ARGV[1])
+remove(ARGV[1])
+if isExpired then
+	return nil
+end
+return v
+`
+	MapCacheSizeScript = mapCacheScriptHeader + `
+local n = redis.call('hlen', KEYS[1])
+for _ in pairs(expiredFields()) do
+	n = n - 1
+end
+return n
+`
+	MapCacheReadAllScript = mapCacheScriptHeader + `
+local fields = expiredFields()
+local entries = redis.call('hgetall', KEYS[1])
+local res = {}
+for i = 1, #entries, 2 do
+	if not fields[entries[i]] then
+		table.insert(res, entries[i])
+		table.insert(res, entries[i + 1])
+	end
+end
+return res
+`
+	// ARGV[1] 每次最多清理的数量
+	MapCacheEvictScript = mapCacheScriptHeader + `
+local n = 0
+for _, key in ipairs({KEYS[2], KEYS[3]}) do
+	for _, field in ipairs(redis.call('zrangebyscore', key, '-inf', now, 'limit', 0, ARGV[1])) do
+		remove(field)
+		n = n + 1
+	end
+end
+return n
+`
+)
+
+var (
+	mapCacheGetScripter         = gredis.NewScript(MapCacheGetScript)
+	mapCachePutScripter         = gredis.NewScript(MapCachePutScript)
+	mapCachePutIfAbsentScripter = gredis.NewScript(MapCachePutIfAbsentScript)
+	mapCacheRemoveScripter      = gredis.NewScript(MapCacheRemoveScript)
+	mapCacheSizeScripter        = gredis.NewScript(MapCacheSizeScript)
+	mapCacheReadAllScripter     = gredis.NewScript(MapCacheReadAllScript)
+	mapCacheEvictScripter       = gredis.NewScript(MapCacheEvictScript)
+)
+
+// MapCache 支持单个entry设置过期时间和最大空闲时间的map
+// 过期信息保存在关联的有序集合中,读取时通过脚本过滤过期的entry,后台清理任务负责物理删除
+type MapCache[K comparable, V any] struct {
+	object
+	hash     *Map[K, V] // 复用Map的编解码
+	store    *mapStore[K, V]
+	eviction *evictionTask
+}
+
+/**
+ * 获取MapCache
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *MapCache[K, V]
+ */
+func GetMapCache[K comparable, V any](r *Redis, name string) *MapCache[K, V] {
+	return GetMapCacheWithCodec[K, V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的MapCache
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *MapCache[K, V]
+ */
+func GetMapCacheWithCodec[K comparable, V any](r *Redis, name string, c codec.Codec) *MapCache[K, V] {
+	o := r.newObject(name, c)
+	return &MapCache[K, V]{object: o, hash: &Map[K, V]{object: o}}
+}
+
+/**
+ * 获取关联外部存储的MapCache,从外部存储加载的entry不过期,使用完后需要调用Close
+ *
+ * param: *Redis           r
+ * param: string           name
+ * param: codec.Codec      c    为nil时使用客户端默认的codec
+ * param: MapOptions[K, V] opts
+ * return: *MapCache[K, V]
+ */
+func GetMapCacheWithOptions[K comparable, V any](r *Redis, name string, c codec.Codec, opts MapOptions[K, V]) *MapCache[K, V] {
+	m := GetMapCacheWithCodec[K, V](r, name, c)
+	m.store = newMapStore(opts)
+	return m
+}
+
+/**
+ * 启动后台清理任务,多个实例同时启动时通过分布式锁保证只有一个实例在清理
+ *
+ * param: time.Duration interval
+ */
+func (m *MapCache[K, V]) StartEviction(interval time.Duration) {
+	if m.eviction != nil {
+		return
+	}
+	m.eviction = m.r.startEviction(suffixName(m.name, "eviction"), interval, m.evict)
+}
+
+/**
+ * 停止后台清理任务
+ */
+func (m *MapCache[K, V]) StopEviction() {
+	m.eviction.close()
+}
+
+/**
+ * 读取key对应的值,会刷新空闲时间,配置了Loader时从外部存储加载不存在的key
+ *
+ * param: K key
+ * return: V
+ * return: bool 是否存在
+ * return: error
+ */
+func (m *MapCache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	var v V
+	field, err := m.encode(key)
+	if err != nil {
+		return v, false, err
+	}
+	v, ok, err := decodeCmd[V](m.codec, mapCacheGetScripter.Run(ctx, m.r, m.keys(), field))
+	loader := m.store.loader()
+	if err != nil || ok || loader == nil {
+		return v, ok, err
+	}
+	if v, ok, err = loader.Load(ctx, key); err != nil || !ok {
+		return v, false, err
+	}
+	// 加载期间其他客户端已经写入时以redis中的值为准
+	old, loaded, err := m.put(ctx, mapCachePutIfAbsentScripter, key, v, 0, 0)
+	if loaded {
+		return old, true, err
+	}
+	return v, err == nil, err
+}
+
+/**
+ * key是否存在,会刷新空闲时间
+ *
+ * param: K key
+ * return: bool
+ * return: error
+ */
+func (m *MapCache[K, V]) ContainsKey(ctx context.Context, key K) (bool, error) {
+	_, ok, err := m.Get(ctx, key)
+	return ok, err
+}
+
+/**
+ * 设置值并返回旧值
+ *
+ * param: K             key
+ * param: V             value
+ * param: time.Duration ttl     为0时不过期
+ * param: time.Duration maxIdle 为0时不限制空闲时间
+ * return: V    旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (m *MapCache[K, V]) Put(ctx context.Context, key K, value V, ttl, maxIdle time.Duration) (V, bool, error) {
+	old, loaded, err := m.put(ctx, mapCachePutScripter, key, value, ttl, maxIdle)
+	if err != nil {
+		return old, loaded, err
+	}
+	return old, loaded, m.store.write(ctx, key, value)
+}
+
+/**
+ * key不存在时设置值
+ *
+ * param: K             key
+ * param: V             value
+ * param: time.Duration ttl     为0时不过期
+ * param: time.Duration maxIdle 为0时不限制空闲时间
+ * return: V    已经存在的值
+ * return: bool 是否已经存在,存在时不会设置
+ * return: error
+ */
+func (m *MapCache[K, V]) PutIfAbsent(ctx context.Context, key K, value V, ttl, maxIdle time.Duration) (V, bool, error) {
+	old, loaded, err := m.put(ctx, mapCachePutIfAbsentScripter, key, value, ttl, maxIdle)
+	if err != nil || loaded {
+		return old, loaded, err
+	}
+	return old, loaded, m.store.write(ctx, key, value)
+}
+
+/**
+ * 设置值,不返回旧值
+ *
+ * param: K             key
+ * param: V             value
+ * param: time.Duration ttl     为0时不过期
+ * param: time.Duration maxIdle 为0时不限制空闲时间
+ * return: bool 是否是新增的key
+ * return: error
+ */
+func (m *MapCache[K, V]) FastPut(ctx context.Context, key K, value V, ttl, maxIdle time.Duration) (bool, error) {
+	_, loaded, err := m.Put(ctx, key, value, ttl, maxIdle)
+	return !loaded, err
+}
+
+/**
+ * 删除key并返回旧值,配置了Writer时同时从外部存储删除
+ *
+ * param: K key
+ * return: V    旧值
+ * return: bool 旧值是否存在
+ * return: error
+ */
+func (m *MapCache[K, V]) Remove(ctx context.Context, key K) (V, bool, error) {
+	var v V
+	field, err := m.encode(key)
+	if err != nil {
+		return v, false, err
+	}
+	old, loaded, err := decodeCmd[V](m.codec, mapCacheRemoveScripter.Run(ctx, m.r, m.keys(), field))
+	if err != nil {
+		return old, loaded, err
+	}
+	return old, loaded, m.store.delete(ctx, key)
+}
+
+/**
+ * 未过期的key的数量
+ *
+ * return: int64
+ * return: error
+ */
+func (m *MapCache[K, V]) Size(ctx context.Context) (int64, error) {
+	return mapCacheSizeScripter.Run(ctx, m.r, m.keys()).Int64()
+}
+
+/**
+ * 读取全部未过期的内容
+ *
+ * return: map[K]V
+ * return: error
+ */
+func (m *MapCache[K, V]) ReadAllMap(ctx context.Context) (map[K]V, error) {
+	entries, err := stringSlice(mapCacheReadAllScripter.Run(ctx, m.r, m.keys()))
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[K]V, len(entries)/2)
+	for i := 0; i+1 < len(entries); i += 2 {
+		key, value, err := m.hash.decodeEntry(entries[i], entries[i+1])
+		if err != nil {
+			return nil, err
+		}
+		res[key] = value
+	}
+	return res, nil
+}
+
+/**
+ * 删除对象及过期信息
+ *
+ * return: bool 删除前是否存在
+ * return: error
+ */
+func (m *MapCache[K, V]) Delete(ctx context.Context) (bool, error) {
+	n, err := m.r.Del(ctx, m.keys()...).Result()
+	return n > 0, err
+}
+
+/**
+ * 停止后台清理任务和异步写入,剩余的修改写入外部存储,可以重复调用
+ */
+func (m *MapCache[K, V]) Close() {
+	m.StopEviction()
+	m.store.close()
+}
+
+func (m *MapCache[K, V]) put(ctx context.Context, script *gredis.Script, key K, value V, ttl, maxIdle time.Duration) (V, bool, error) {
+	field, data, err := m.hash.encodeEntry(key, value)
+	if err != nil {
+		var v V
+		return v, false, err
+	}
+	return decodeCmd[V](m.codec, script.Run(ctx, m.r, m.keys(), field, data, ttl.Milliseconds(), maxIdle.Milliseconds()))
+}
+
+func (m *MapCache[K, V]) evict(ctx context.Context) error {
+	for {
+		n, err := mapCacheEvictScripter.Run(ctx, m.r, m.keys(), MapCacheEvictionBatch).Int()
+		if err != nil || n < MapCacheEvictionBatch {
+			return err
+		}
+	}
+}
+
+func (m *MapCache[K, V]) keys() []string {
+	return []string{m.name, suffixName(m.name, "ttl"), suffixName(m.name, "idle"), suffixName(m.name, "maxidle")}
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMapCache_keys(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"cache", []string{"cache", "{cache}:ttl", "{cache}:idle", "{cache}:maxidle"}},
+		{"app:{cache}", []string{"app:{cache}", "app:{cache}:ttl", "app:{cache}:idle", "app:{cache}:maxidle"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MapCache[string, string]{}
+			m.name = tt.name
+			keys := m.keys()
+			for i, key := range keys {
+				if key != tt.want[i] {
+					t.Errorf("keys()[%d] = %v, want %v", i, key, tt.want[i])
+				}
+				// 脚本同时访问全部key,需要位于同一个slot
+				if keySlot(key) != keySlot(tt.name) {
+					t.Errorf("keys()[%d] = %v is not in the slot of %v", i, key, tt.name)
+				}
+			}
+		})
+	}
+}
+
+func TestMapCache_expiry(t *testing.T) {
+	ctx := context.Background()
+	m := GetMapCache[string, string](testRedis(t), "cache")
+	if _, err := m.Delete(ctx); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	puts := []struct {
+		key          string
+		ttl, maxIdle time.Duration
+	}{
+		{"forever", 0, 0},
+		{"ttl", 100 * time.Millisecond, 0},
+		{"idle", 0, 100 * time.Millisecond},
+		{"touched", 0, 100 * time.Millisecond},
+	}
+	for _, p := range puts {
+		if _, err := m.FastPut(ctx, p.key, p.key, p.ttl, p.maxIdle); err != nil {
+			t.Fatalf("FastPut() error = %v", err)
+		}
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, ok, err := m.Get(ctx, "touched"); err != nil || !ok {
+		t.Fatalf("Get() = %v, %v, want true", ok, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"forever", true},
+		{"ttl", false},
+		{"idle", false},
+		{"touched", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if _, ok, err := m.Get(ctx, tt.key); err != nil || ok != tt.want {
+				t.Errorf("Get() = %v, %v, want %v", ok, err, tt.want)
+			}
+		})
+	}
+	if n, err := m.Size(ctx); err != nil || n != 2 {
+		t.Errorf("Size() = %v, %v, want 2", n, err)
+	}
+	if err := m.evict(ctx); err != nil {
+		t.Fatalf("evict() error = %v", err)
+	}
+	if n, err := m.r.HLen(ctx, m.name).Result(); err != nil || n != 2 {
+		t.Errorf("HLen() after evict = %v, %v, want 2", n, err)
+	}
+}
@@ -0,0 +1,240 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWriteBehindDelay     = time.Second
+	DefaultWriteBehindBatchSize = 50
+)
+
+// MapLoader 读取map时key不存在,从外部存储加载
+type MapLoader[K comparable, V any] interface {
+	/**
+	 * 加载key对应的值
+	 *
+	 * param: K key
+	 * return: V
+	 * return: bool 外部存储中是否存在
+	 * return: error
+	 */
+	Load(ctx context.Context, key K) (V, bool, error)
+}
+
+// MapWriter 修改map后同步写入外部存储
+type MapWriter[K comparable, V any] interface {
+	/**
+	 * 写入新增或修改的entry
+	 *
+	 * param: map[K]V entries
+	 * return: error
+	 */
+	Write(ctx context.Context, entries map[K]V) error
+
+	/**
+	 * 删除key
+	 *
+	 * param: []K keys
+	 * return: error
+	 */
+	Delete(ctx context.Context, keys []K) error
+}
+
+// WriteMode MapWriter的写入方式
+type WriteMode int
+
+const (
+	WriteModeThrough WriteMode = iota // 修改redis后同步写入,写入失败时返回错误
+	WriteModeBehind                   // 修改redis后异步批量写入,写入失败时调用OnWriteError
+)
+
+// MapOptions Map和MapCache的外部存储配置
+type MapOptions[K comparable, V any] struct {
+	Loader               MapLoader[K, V]
+	Writer               MapWriter[K, V]
+	WriteMode            WriteMode
+	WriteBehindDelay     time.Duration // 异步写入的间隔,0时使用 DefaultWriteBehindDelay
+	WriteBehindBatchSize int           // 异步写入每批的最大entry数,积累到该数量时立即写入,0时使用 DefaultWriteBehindBatchSize
+	WriteRetryAttempts   int           // 写入失败后的重试次数
+	WriteRetryInterval   time.Duration // 重试间隔
+	// 异步写入重试后仍然失败时调用,written为写入失败的entry,deleted为删除失败的key
+	OnWriteError func(err error, written map[K]V, deleted []K)
+}
+
+// mapStore Map与外部存储之间的读写
+type mapStore[K comparable, V any] struct {
+	opts    MapOptions[K, V]
+	behind  bool
+	mu      sync.Mutex
+	pending map[K]mapWriteOp[V] // 等待异步写入的修改,同一个key只保留最后一次
+	flush   chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+type mapWriteOp[V any] struct {
+	value   V
+	deleted bool
+}
+
+func newMapStore[K comparable, V any](opts MapOptions[K, V]) *mapStore[K, V] {
+	if opts.WriteBehindDelay <= 0 {
+		opts.WriteBehindDelay = DefaultWriteBehindDelay
+	}
+	if opts.WriteBehindBatchSize <= 0 {
+		opts.WriteBehindBatchSize = DefaultWriteBehindBatchSize
+	}
+	s := &mapStore[K, V]{opts: opts, behind: opts.Writer != nil && opts.WriteMode == WriteModeBehind}
+	if s.behind {
+		s.pending = make(map[K]mapWriteOp[V])
+		s.flush = make(chan struct{}, 1)
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.run()
+	}
+	return s
+}
+
+func (s *mapStore[K, V]) loader() MapLoader[K, V] {
+	if s == nil {
+		return nil
+	}
+	return s.opts.Loader
+}
+
+func (s *mapStore[K, V]) write(ctx context.Context, key K, value V) error {
+	if s == nil || s.opts.Writer == nil {
+		return nil
+	}
+	if s.behind {
+		s.enqueue(map[K]mapWriteOp[V]{key: {value: value}})
+		return nil
+	}
+	return s.retry(ctx, func() error {
+		return s.opts.Writer.Write(ctx, map[K]V{key: value})
+	})
+}
+
+func (s *mapStore[K, V]) delete(ctx context.Context, keys ...K) error {
+	if s == nil || s.opts.Writer == nil || len(keys) == 0 {
+		return nil
+	}
+	if s.behind {
+		ops := make(map[K]mapWriteOp[V], len(keys))
+		for _, key := range keys {
+			ops[key] = mapWriteOp[V]{deleted: true}
+		}
+		s.enqueue(ops)
+		return nil
+	}
+	return s.retry(ctx, func() error {
+		return s.opts.Writer.Delete(ctx, keys)
+	})
+}
+
+func (s *mapStore[K, V]) enqueue(ops map[K]mapWriteOp[V]) {
+	s.mu.Lock()
+	for key, op := range ops {
+		s.pending[key] = op
+	}
+	full := len(s.pending) >= s.opts.WriteBehindBatchSize
+	s.mu.Unlock()
+	if full {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *mapStore[K, V]) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.WriteBehindDelay)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			s.writePending(context.Background())
+			return
+		case <-ticker.C:
+		case <-s.flush:
+		}
+		s.writePending(context.Background())
+	}
+}
+
+/**
+ * 按批写入所有等待中的修改
+ */
+func (s *mapStore[K, V]) writePending(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[K]mapWriteOp[V])
+	s.mu.Unlock()
+	written := make(map[K]V)
+	var deleted []K
+	for key, op := range pending {
+		if op.deleted {
+			deleted = append(deleted, key)
+		} else {
+			written[key] = op.value
+		}
+		if len(written)+len(deleted) >= s.opts.WriteBehindBatchSize {
+			s.writeBatch(ctx, written, deleted)
+			written, deleted = make(map[K]V), nil
+		}
+	}
+	if len(written)+len(deleted) > 0 {
+		s.writeBatch(ctx, written, deleted)
+	}
+}
+
+func (s *mapStore[K, V]) writeBatch(ctx context.Context, written map[K]V, deleted []K) {
+	var err error
+	if len(written) > 0 {
+		err = s.retry(ctx, func() error {
+			return s.opts.Writer.Write(ctx, written)
+		})
+		if err != nil && s.opts.OnWriteError != nil {
+			s.opts.OnWriteError(err, written, nil)
+		}
+	}
+	if len(deleted) > 0 {
+		err = s.retry(ctx, func() error {
+			return s.opts.Writer.Delete(ctx, deleted)
+		})
+		if err != nil && s.opts.OnWriteError != nil {
+			s.opts.OnWriteError(err, nil, deleted)
+		}
+	}
+}
+
+func (s *mapStore[K, V]) retry(ctx context.Context, f func() error) error {
+	err := f()
+	for i := 0; err != nil && i < s.opts.WriteRetryAttempts; i++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(s.opts.WriteRetryInterval):
+		}
+		err = f()
+	}
+	return err
+}
+
+/**
+ * 停止异步写入并写入剩余的修改,可以重复调用
+ */
+func (s *mapStore[K, V]) close() {
+	if s == nil || !s.behind {
+		return
+	}
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
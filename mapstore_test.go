@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errWrite = errors.New("write failed")
+
+// fakeMapWriter 记录写入和删除,前failures次调用返回错误
+type fakeMapWriter struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	batches  int
+	written  map[string]int
+	deleted  []string
+}
+
+func (w *fakeMapWriter) Write(ctx context.Context, entries map[string]int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.calls++; w.calls <= w.failures {
+		return errWrite
+	}
+	w.batches++
+	if w.written == nil {
+		w.written = make(map[string]int)
+	}
+	for k, v := range entries {
+		w.written[k] = v
+	}
+	return nil
+}
+
+func (w *fakeMapWriter) Delete(ctx context.Context, keys []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.calls++; w.calls <= w.failures {
+		return errWrite
+	}
+	w.batches++
+	w.deleted = append(w.deleted, keys...)
+	return nil
+}
+
+func TestMapStore_writeThrough(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		attempts  int
+		wantErr   error
+		wantCalls int
+	}{
+		{"ok", 0, 0, nil, 1},
+		{"retry succeeds", 2, 2, nil, 3},
+		{"retry exhausted", 3, 2, errWrite, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fakeMapWriter{failures: tt.failures}
+			s := newMapStore[string, int](MapOptions[string, int]{Writer: w, WriteRetryAttempts: tt.attempts, WriteRetryInterval: time.Millisecond})
+			if err := s.write(context.Background(), "a", 1); err != tt.wantErr {
+				t.Fatalf("write() error = %v, want %v", err, tt.wantErr)
+			}
+			if w.calls != tt.wantCalls {
+				t.Errorf("calls = %v, want %v", w.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestMapStore_writeBehind(t *testing.T) {
+	type op struct {
+		key    string
+		value  int
+		delete bool
+	}
+	tests := []struct {
+		name        string
+		batchSize   int
+		failures    int
+		ops         []op
+		wantWritten map[string]int
+		wantDeleted []string
+		wantBatches int
+		wantErrors  int
+	}{
+		{"last write wins", 10, 0, []op{{"a", 1, false}, {"a", 2, false}, {"b", 3, false}},
+			map[string]int{"a": 2, "b": 3}, nil, 1, 0},
+		{"delete after write", 10, 0, []op{{"a", 1, false}, {"a", 0, true}, {"b", 3, false}},
+			map[string]int{"b": 3}, []string{"a"}, 2, 0},
+		{"split batches", 2, 0, []op{{"a", 1, false}, {"b", 2, false}, {"c", 3, false}},
+			map[string]int{"a": 1, "b": 2, "c": 3}, nil, 2, 0},
+		{"failure reported", 10, 1, []op{{"a", 1, false}},
+			nil, nil, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fakeMapWriter{failures: tt.failures}
+			errs := 0
+			s := newMapStore[string, int](MapOptions[string, int]{
+				Writer:               w,
+				WriteMode:            WriteModeBehind,
+				WriteBehindDelay:     time.Hour, // 只在close时写入
+				WriteBehindBatchSize: tt.batchSize,
+				OnWriteError: func(err error, written map[string]int, deleted []string) {
+					errs++
+				},
+			})
+			ctx := context.Background()
+			for _, o := range tt.ops {
+				if o.delete {
+					_ = s.delete(ctx, o.key)
+				} else {
+					_ = s.write(ctx, o.key, o.value)
+				}
+			}
+			s.close()
+			s.close()
+			sort.Strings(w.deleted)
+			if !reflect.DeepEqual(w.written, tt.wantWritten) || !reflect.DeepEqual(w.deleted, tt.wantDeleted) {
+				t.Errorf("written = %v, deleted = %v, want %v, %v", w.written, w.deleted, tt.wantWritten, tt.wantDeleted)
+			}
+			if w.batches != tt.wantBatches || errs != tt.wantErrors {
+				t.Errorf("batches = %v, errors = %v, want %v, %v", w.batches, errs, tt.wantBatches, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCConfig gRPC 限流拦截器配置
+type GRPCConfig struct {
+	Limiter Limiter
+	// 从请求中提取限流key,返回空字符串时不限流,默认按客户端IP
+	KeyFunc func(ctx context.Context, fullMethod string) string
+	// redis不可用时是否放行,为false时返回 codes.Unavailable
+	FailOpen bool
+	// 限流器出错时的回调
+	OnError func(ctx context.Context, fullMethod string, err error)
+}
+
+/**
+ * 创建一元调用限流拦截器
+ *
+ * param: GRPCConfig c
+ * return: grpc.UnaryServerInterceptor
+ */
+func UnaryServerInterceptor(c GRPCConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := c.allow(ctx, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+/**
+ * 创建流式调用限流拦截器,只在建立流时检查一次
+ *
+ * param: GRPCConfig c
+ * return: grpc.StreamServerInterceptor
+ */
+func StreamServerInterceptor(c GRPCConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := c.allow(ss.Context(), info.FullMethod, ss.SetHeader); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (c GRPCConfig) allow(ctx context.Context, fullMethod string, setHeader func(metadata.MD) error) error {
+	keyFunc := c.KeyFunc
+	if keyFunc == nil {
+		keyFunc = GRPCKeyByPeer
+	}
+	key := keyFunc(ctx, fullMethod)
+	if key == "" {
+		return nil
+	}
+	res, err := c.Limiter.Allow(ctx, key)
+	if err != nil {
+		if c.OnError != nil {
+			c.OnError(ctx, fullMethod, err)
+		}
+		if c.FailOpen {
+			return nil
+		}
+		return status.Error(codes.Unavailable, "rate limiter unavailable")
+	}
+	md := metadata.MD{}
+	for _, h := range rateLimitHeaders(res) {
+		md.Set(strings.ToLower(h[0]), h[1])
+	}
+	_ = setHeader(md)
+	if !res.Allowed {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+/**
+ * 按客户端IP限流
+ *
+ * param: context.Context ctx
+ * param: string          fullMethod
+ * return: string
+ */
+func GRPCKeyByPeer(ctx context.Context, fullMethod string) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+/**
+ * 按请求元数据限流
+ *
+ * param: string name
+ * return: func(context.Context, string) string
+ */
+func GRPCKeyByMetadata(name string) func(ctx context.Context, fullMethod string) string {
+	return func(ctx context.Context, fullMethod string) string {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(name)
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+}
+
+/**
+ * 按调用方法限流
+ *
+ * param: context.Context ctx
+ * param: string          fullMethod
+ * return: string
+ */
+func GRPCKeyByMethod(ctx context.Context, fullMethod string) string {
+	return fullMethod
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+)
+
+// HTTPConfig net/http 限流中间件配置
+type HTTPConfig struct {
+	Limiter Limiter
+	// 从请求中提取限流key,返回空字符串时不限流,默认按客户端IP
+	KeyFunc func(r *http.Request) string
+	// redis不可用时是否放行,为false时返回503
+	FailOpen bool
+	// 限流器出错时的回调
+	OnError func(r *http.Request, err error)
+}
+
+/**
+ * 创建 net/http 限流中间件
+ *
+ * param: HTTPConfig c
+ * return: func(http.Handler) http.Handler
+ */
+func HTTP(c HTTPConfig) func(http.Handler) http.Handler {
+	if c.KeyFunc == nil {
+		c.KeyFunc = KeyByIP
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := c.KeyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			res, err := c.Limiter.Allow(r.Context(), key)
+			if err != nil {
+				if c.OnError != nil {
+					c.OnError(r, err)
+				}
+				if c.FailOpen {
+					next.ServeHTTP(w, r)
+				} else {
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				}
+				return
+			}
+			for _, h := range rateLimitHeaders(res) {
+				w.Header().Set(h[0], h[1])
+			}
+			if !res.Allowed {
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+/**
+ * 按客户端IP限流
+ *
+ * param: *http.Request r
+ * return: string
+ */
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+/**
+ * 按请求头限流
+ *
+ * param: string name
+ * return: func(*http.Request) string
+ */
+func KeyByHeader(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+/**
+ * 按请求方法和路径限流
+ *
+ * param: *http.Request r
+ * return: string
+ */
+func KeyByMethod(r *http.Request) string {
+	return r.Method + " " + r.URL.Path
+}
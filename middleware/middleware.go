@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ainiaa/go-redisson"
+)
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Limiter 根据key判断请求是否允许通过
+type Limiter interface {
+	Allow(ctx context.Context, key string) (*redis.RateLimitResult, error)
+}
+
+// LimiterFunc 函数形式的Limiter
+type LimiterFunc func(ctx context.Context, key string) (*redis.RateLimitResult, error)
+
+func (f LimiterFunc) Allow(ctx context.Context, key string) (*redis.RateLimitResult, error) {
+	return f(ctx, key)
+}
+
+/**
+ * 基于滑动窗口日志的Limiter,每个key对应一个限流器
+ *
+ * param: *redis.Redis  r
+ * param: string        prefix
+ * param: int64         limit
+ * param: time.Duration window
+ * return: Limiter
+ */
+func SlidingWindowLog(r *redis.Redis, prefix string, limit int64, window time.Duration) Limiter {
+	return LimiterFunc(func(ctx context.Context, key string) (*redis.RateLimitResult, error) {
+		return r.GetSlidingWindowLog(prefix+key, limit, window).Allow(ctx)
+	})
+}
+
+/**
+ * 基于滑动窗口计数的Limiter,每个key对应一个限流器
+ *
+ * param: *redis.Redis  r
+ * param: string        prefix
+ * param: int64         limit
+ * param: time.Duration window
+ * return: Limiter
+ */
+func SlidingWindowCounter(r *redis.Redis, prefix string, limit int64, window time.Duration) Limiter {
+	return LimiterFunc(func(ctx context.Context, key string) (*redis.RateLimitResult, error) {
+		return r.GetSlidingWindowCounter(prefix+key, limit, window).Allow(ctx)
+	})
+}
+
+/**
+ * 基于令牌桶的Limiter,每个key对应一个限流器,第一次使用时设置速率
+ * 每个key的速率配置会一直保存在redis中,key数量没有上限时优先使用滑动窗口
+ *
+ * param: *redis.Redis   r
+ * param: string         prefix
+ * param: redis.RateType rateType
+ * param: int64          rate     每个interval内产生的令牌数
+ * param: time.Duration  interval
+ * return: Limiter
+ */
+func TokenBucket(r *redis.Redis, prefix string, rateType redis.RateType, rate int64, interval time.Duration) Limiter {
+	return LimiterFunc(func(ctx context.Context, key string) (*redis.RateLimitResult, error) {
+		l := r.GetRateLimiter(prefix + key)
+		res, err := l.Allow(ctx)
+		if err != redis.ErrRateLimiterNotInit {
+			return res, err
+		}
+		if _, err = l.TrySetRate(ctx, rateType, rate, interval); err != nil {
+			return nil, err
+		}
+		return l.Allow(ctx)
+	})
+}
+
+/**
+ * 将限流结果转换为响应头,时间向上取整到秒
+ *
+ * param: *redis.RateLimitResult res
+ * return: [][2]string
+ */
+func rateLimitHeaders(res *redis.RateLimitResult) [][2]string {
+	headers := [][2]string{
+		{HeaderLimit, strconv.FormatInt(res.Limit, 10)},
+		{HeaderRemaining, strconv.FormatInt(res.Remaining, 10)},
+		{HeaderReset, seconds(res.ResetAfter)},
+	}
+	if !res.Allowed {
+		headers = append(headers, [2]string{HeaderRetryAfter, seconds(res.RetryAfter)})
+	}
+	return headers
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
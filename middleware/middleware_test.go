@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ainiaa/go-redisson"
+)
+
+func fixedLimiter(res *redis.RateLimitResult, err error) Limiter {
+	return LimiterFunc(func(ctx context.Context, key string) (*redis.RateLimitResult, error) {
+		return res, err
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	tests := []struct {
+		name       string
+		config     HTTPConfig
+		wantCode   int
+		wantHeader map[string]string
+	}{
+		{"allowed", HTTPConfig{Limiter: fixedLimiter(&redis.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 1500 * time.Millisecond}, nil)},
+			http.StatusOK, map[string]string{HeaderLimit: "10", HeaderRemaining: "9", HeaderReset: "2", HeaderRetryAfter: ""}},
+		{"denied", HTTPConfig{Limiter: fixedLimiter(&redis.RateLimitResult{Allowed: false, Limit: 10, RetryAfter: 300 * time.Millisecond, ResetAfter: time.Second}, nil)},
+			http.StatusTooManyRequests, map[string]string{HeaderRemaining: "0", HeaderRetryAfter: "1"}},
+		{"fail-open", HTTPConfig{Limiter: fixedLimiter(nil, errors.New("down")), FailOpen: true}, http.StatusOK, nil},
+		{"fail-closed", HTTPConfig{Limiter: fixedLimiter(nil, errors.New("down"))}, http.StatusServiceUnavailable, nil},
+		{"empty-key", HTTPConfig{Limiter: fixedLimiter(nil, errors.New("down")), KeyFunc: KeyByHeader("X-Api-Key")}, http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			HTTP(tt.config)(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.wantCode {
+				t.Errorf("HTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+			for k, v := range tt.wantHeader {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("HTTP() header %s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	tests := []struct {
+		name     string
+		config   GRPCConfig
+		wantCode codes.Code
+	}{
+		{"allowed", GRPCConfig{Limiter: fixedLimiter(&redis.RateLimitResult{Allowed: true, Limit: 1}, nil), KeyFunc: GRPCKeyByMethod}, codes.OK},
+		{"denied", GRPCConfig{Limiter: fixedLimiter(&redis.RateLimitResult{Allowed: false, Limit: 1}, nil), KeyFunc: GRPCKeyByMethod}, codes.ResourceExhausted},
+		{"fail-open", GRPCConfig{Limiter: fixedLimiter(nil, errors.New("down")), KeyFunc: GRPCKeyByMethod, FailOpen: true}, codes.OK},
+		{"fail-closed", GRPCConfig{Limiter: fixedLimiter(nil, errors.New("down")), KeyFunc: GRPCKeyByMethod}, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnaryServerInterceptor(tt.config)(context.Background(), nil, info, handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("UnaryServerInterceptor() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
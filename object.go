@@ -0,0 +1,138 @@
+package redis
+
+import (
+	"context"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+// object 所有数据结构对象的公共部分
+type object struct {
+	r     *Redis
+	name  string
+	codec codec.Codec
+}
+
+/**
+ * 创建对象,c为nil时使用客户端默认的codec
+ *
+ * param: string      name
+ * param: codec.Codec c
+ * return: object
+ */
+func (r *Redis) newObject(name string, c codec.Codec) object {
+	if c == nil {
+		c = r.DefaultCodec()
+	}
+	return object{r: r, name: r.namespaced(name), codec: c}
+}
+
+/**
+ * 对象在redis中的key
+ *
+ * return: string
+ */
+func (o *object) Name() string {
+	return o.name
+}
+
+/**
+ * 对象是否存在
+ *
+ * return: bool
+ * return: error
+ */
+func (o *object) IsExists(ctx context.Context) (bool, error) {
+	n, err := o.r.Exists(ctx, o.name).Result()
+	return n > 0, err
+}
+
+/**
+ * 删除对象
+ *
+ * return: bool 删除前是否存在
+ * return: error
+ */
+func (o *object) Delete(ctx context.Context) (bool, error) {
+	n, err := o.r.Del(ctx, o.name).Result()
+	return n > 0, err
+}
+
+func (o *object) encode(v interface{}) (string, error) {
+	data, err := o.codec.Encode(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (o *object) decode(data string, v interface{}) error {
+	return o.codec.Decode([]byte(data), v)
+}
+
+func (o *object) isCluster() bool {
+	return o.r.isCluster()
+}
+
+func (r *Redis) isCluster() bool {
+	_, ok := r.UniversalClient.(*gredis.ClusterClient)
+	return ok
+}
+
+func (r *Redis) isRing() bool {
+	_, ok := r.UniversalClient.(*gredis.Ring)
+	return ok
+}
+
+/**
+ * 使用codec解码为指定类型
+ *
+ * param: codec.Codec c
+ * param: string      data
+ * return: T
+ * return: error
+ */
+func decodeAs[T any](c codec.Codec, data string) (T, error) {
+	var v T
+	err := c.Decode([]byte(data), &v)
+	return v, err
+}
+
+/**
+ * 去掉元素前面固定长度的前缀,长度不足时说明元素不是通过当前对象写入的
+ *
+ * param: string member
+ * param: int    n 前缀长度
+ * return: string
+ * return: error
+ */
+func stripPrefix(member string, n int) (string, error) {
+	if len(member) < n {
+		return "", ErrMalformedMember
+	}
+	return member[n:], nil
+}
+
+/**
+ * 解码脚本返回的旧值,脚本返回nil时表示旧值不存在
+ *
+ * param: codec.Codec c
+ * param: *gredis.Cmd cmd
+ * return: T
+ * return: bool
+ * return: error
+ */
+func decodeCmd[T any](c codec.Codec, cmd *gredis.Cmd) (T, bool, error) {
+	var v T
+	data, err := cmd.Text()
+	if err == gredis.Nil {
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	v, err = decodeAs[T](c, data)
+	return v, err == nil, err
+}
@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"testing"
+)
+
+func TestStripPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  string
+		n       int
+		want    string
+		wantErr error
+	}{
+		{"value", "0000000000000001\"a\"", 16, "\"a\"", nil},
+		{"empty value", "0000000000000001", 16, "", nil},
+		{"too short", "00001", 16, "", ErrMalformedMember},
+		{"empty", "", 16, "", ErrMalformedMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripPrefix(tt.member, tt.n)
+			if err != tt.wantErr {
+				t.Fatalf("stripPrefix() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("stripPrefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
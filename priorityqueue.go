@@ -0,0 +1,263 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	// 元素前面加上16位十六进制的递增序号,相同优先级的元素按添加顺序排列
+	priorityQueueSeqLen = 16
+
+	// KEYS[1] 队列 KEYS[2] 序号 ARGV依次为优先级和元素
+	PriorityQueueOfferScript = `
+local seq = redis.call('incrby', KEYS[2], #ARGV / 2) - #ARGV / 2
+for i = 1, #ARGV, 2 do
+	seq = seq + 1
+	redis.call('zadd', KEYS[1], ARGV[i], string.format('%016x', seq) .. ARGV[i + 1])
+end
+return redis.call('zcard', KEYS[1])
+`
+)
+
+var priorityQueueOfferScripter = gredis.NewScript(PriorityQueueOfferScript)
+
+// PriorityQueue 优先级队列,优先级数值小的元素先出队,优先级相同时先进先出
+type PriorityQueue[V any] struct {
+	object
+	seq string
+}
+
+// PriorityBlockingQueue 支持阻塞读取的优先级队列,阻塞命令使用独立的连接池
+type PriorityBlockingQueue[V any] struct {
+	PriorityQueue[V]
+}
+
+// PriorityEntry 元素及其优先级
+type PriorityEntry[V any] struct {
+	Priority float64
+	Value    V
+}
+
+/**
+ * 获取PriorityQueue
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *PriorityQueue[V]
+ */
+func GetPriorityQueue[V any](r *Redis, name string) *PriorityQueue[V] {
+	return GetPriorityQueueWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的PriorityQueue
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *PriorityQueue[V]
+ */
+func GetPriorityQueueWithCodec[V any](r *Redis, name string, c codec.Codec) *PriorityQueue[V] {
+	o := r.newObject(name, c)
+	return &PriorityQueue[V]{object: o, seq: suffixName(o.name, "seq")}
+}
+
+/**
+ * 获取PriorityBlockingQueue
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *PriorityBlockingQueue[V]
+ */
+func GetPriorityBlockingQueue[V any](r *Redis, name string) *PriorityBlockingQueue[V] {
+	return GetPriorityBlockingQueueWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的PriorityBlockingQueue
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *PriorityBlockingQueue[V]
+ */
+func GetPriorityBlockingQueueWithCodec[V any](r *Redis, name string, c codec.Codec) *PriorityBlockingQueue[V] {
+	return &PriorityBlockingQueue[V]{PriorityQueue: *GetPriorityQueueWithCodec[V](r, name, c)}
+}
+
+/**
+ * 添加元素
+ *
+ * param: V       value
+ * param: float64 priority 数值越小越先出队
+ * return: int64 添加后的元素数量
+ * return: error
+ */
+func (q *PriorityQueue[V]) Offer(ctx context.Context, value V, priority float64) (int64, error) {
+	return q.OfferAll(ctx, PriorityEntry[V]{Priority: priority, Value: value})
+}
+
+/**
+ * 按顺序添加多个元素
+ *
+ * param: ...PriorityEntry[V] entries
+ * return: int64 添加后的元素数量
+ * return: error
+ */
+func (q *PriorityQueue[V]) OfferAll(ctx context.Context, entries ...PriorityEntry[V]) (int64, error) {
+	if len(entries) == 0 {
+		return q.Size(ctx)
+	}
+	args := make([]interface{}, 0, len(entries)*2)
+	for _, e := range entries {
+		item, err := q.encode(e.Value)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, e.Priority, item)
+	}
+	return priorityQueueOfferScripter.Run(ctx, q.r, []string{q.name, q.seq}, args...).Int64()
+}
+
+/**
+ * 取出优先级最高的元素
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *PriorityQueue[V]) Poll(ctx context.Context) (V, bool, error) {
+	entry, ok, err := q.PollEntry(ctx)
+	return entry.Value, ok, err
+}
+
+/**
+ * 取出优先级最高的元素及其优先级
+ *
+ * return: PriorityEntry[V]
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *PriorityQueue[V]) PollEntry(ctx context.Context) (PriorityEntry[V], bool, error) {
+	res, err := q.r.ZPopMin(ctx, q.name).Result()
+	if err != nil || len(res) == 0 {
+		return PriorityEntry[V]{}, false, err
+	}
+	return q.decodeEntry(res[0])
+}
+
+/**
+ * 读取优先级最高的元素,不取出
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *PriorityQueue[V]) Peek(ctx context.Context) (V, bool, error) {
+	res, err := q.r.ZRangeWithScores(ctx, q.name, 0, 0).Result()
+	if err != nil || len(res) == 0 {
+		var v V
+		return v, false, err
+	}
+	entry, ok, err := q.decodeEntry(res[0])
+	return entry.Value, ok, err
+}
+
+/**
+ * 元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (q *PriorityQueue[V]) Size(ctx context.Context) (int64, error) {
+	return q.r.ZCard(ctx, q.name).Result()
+}
+
+/**
+ * 按出队顺序读取全部元素
+ *
+ * return: []V
+ * return: error
+ */
+func (q *PriorityQueue[V]) ReadAll(ctx context.Context) ([]V, error) {
+	items, err := q.r.ZRange(ctx, q.name, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		if items[i], err = stripPrefix(item, priorityQueueSeqLen); err != nil {
+			return nil, err
+		}
+	}
+	return decodeValues[V](q.codec)(items, nil)
+}
+
+/**
+ * 删除队列
+ *
+ * return: bool 删除前队列是否存在
+ * return: error
+ */
+func (q *PriorityQueue[V]) Delete(ctx context.Context) (bool, error) {
+	n, err := q.r.Del(ctx, q.name, q.seq).Result()
+	return n > 0, err
+}
+
+func (q *PriorityQueue[V]) decodeEntry(z gredis.Z) (PriorityEntry[V], bool, error) {
+	entry := PriorityEntry[V]{Priority: z.Score}
+	member, _ := z.Member.(string)
+	data, err := stripPrefix(member, priorityQueueSeqLen)
+	if err != nil {
+		return entry, false, err
+	}
+	entry.Value, err = decodeAs[V](q.codec, data)
+	return entry, err == nil, err
+}
+
+/**
+ * 取出优先级最高的元素,队列为空时阻塞直到有元素或ctx结束
+ *
+ * return: V
+ * return: error ctx结束时返回ctx.Err()
+ */
+func (q *PriorityBlockingQueue[V]) Take(ctx context.Context) (V, error) {
+	entry, _, err := q.poll(ctx, -1)
+	return entry.Value, err
+}
+
+/**
+ * 取出优先级最高的元素,队列为空时最多等待timeout
+ *
+ * param: time.Duration timeout 小于等于0时不等待,精度为1秒
+ * return: V
+ * return: bool 超时返回false
+ * return: error
+ */
+func (q *PriorityBlockingQueue[V]) Poll(ctx context.Context, timeout time.Duration) (V, bool, error) {
+	if timeout <= 0 {
+		return q.PriorityQueue.Poll(ctx)
+	}
+	entry, ok, err := q.poll(ctx, timeout)
+	return entry.Value, ok, err
+}
+
+func (q *PriorityBlockingQueue[V]) poll(ctx context.Context, timeout time.Duration) (PriorityEntry[V], bool, error) {
+	var z gredis.Z
+	_, _, ok, err := blockingPoll(ctx, q.r, timeout, func(ctx context.Context, client gredis.UniversalClient) (string, string, error) {
+		res, err := client.BZPopMin(ctx, blockingPollSlice, q.name).Result()
+		if err != nil {
+			return "", "", err
+		}
+		z = res.Z
+		return res.Key, "", nil
+	})
+	if err != nil || !ok {
+		return PriorityEntry[V]{}, ok, err
+	}
+	return q.decodeEntry(z)
+}
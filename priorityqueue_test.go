@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+func TestPriorityQueue_decodeEntry(t *testing.T) {
+	q := &PriorityQueue[string]{object: object{codec: codec.JSON}}
+	tests := []struct {
+		name    string
+		z       gredis.Z
+		want    PriorityEntry[string]
+		wantOk  bool
+		wantErr bool
+	}{
+		{"value", gredis.Z{Score: 2, Member: "0000000000000001\"a\""}, PriorityEntry[string]{Priority: 2, Value: "a"}, true, false},
+		{"too short", gredis.Z{Score: 1, Member: "0001"}, PriorityEntry[string]{Priority: 1}, false, true},
+		{"not a string", gredis.Z{Score: 1, Member: 1}, PriorityEntry[string]{Priority: 1}, false, true},
+		{"bad payload", gredis.Z{Score: 3, Member: "0000000000000001{"}, PriorityEntry[string]{Priority: 3}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := q.decodeEntry(tt.z)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("decodeEntry() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+const (
+	DefaultPubSubPingInterval = 30 * time.Second
+	pubSubReconnectDelay      = 100 * time.Millisecond
+)
+
+// pubSubHandler 订阅连接的事件回调,回调都在接收消息的goroutine中执行
+type pubSubHandler struct {
+	onMessage      func(msg *gredis.Message)
+	onSubscription func(sub *gredis.Subscription) // 收到订阅或取消订阅的确认
+	onDisconnect   func()                         // 连接断开,断开期间的消息会丢失
+	onReconnect    func()                         // 重连并重新订阅成功
+}
+
+/**
+ * 循环接收订阅消息直到ctx结束,长时间没有消息时发送PING检测连接,连接断开时自动重连并重新订阅
+ *
+ * param: *gredis.PubSub ps
+ * param: time.Duration  pingInterval 小于等于0时使用 DefaultPubSubPingInterval
+ * param: pubSubHandler  h
+ */
+func listenPubSub(ctx context.Context, ps *gredis.PubSub, pingInterval time.Duration, h pubSubHandler) {
+	if pingInterval <= 0 {
+		pingInterval = DefaultPubSubPingInterval
+	}
+	disconnected := false
+	for {
+		msg, err := ps.ReceiveTimeout(ctx, pingInterval)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() && !disconnected {
+				// 检测失败时下次读取会重连
+				if ps.Ping(ctx) == nil {
+					continue
+				}
+			}
+			if !disconnected {
+				disconnected = true
+				if h.onDisconnect != nil {
+					h.onDisconnect()
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pubSubReconnectDelay):
+			}
+			continue
+		}
+		switch msg := msg.(type) {
+		case *gredis.Subscription:
+			if disconnected {
+				disconnected = false
+				if h.onReconnect != nil {
+					h.onReconnect()
+				}
+			}
+			if h.onSubscription != nil {
+				h.onSubscription(msg)
+			}
+		case *gredis.Message:
+			if h.onMessage != nil {
+				h.onMessage(msg)
+			}
+		}
+	}
+}
+
+// subscriber 客户端共享的订阅连接,按channel和pattern把消息分发给监听器,目前只有Topic和PatternTopic使用
+// LocalCachedMap、DelayedQueue需要感知连接断开,ClientCache需要独立的连接id接收失效通知,仍然使用各自的订阅连接
+// 连接断开后go-redis重连时会自动重新订阅
+type subscriber struct {
+	ps       *gredis.PubSub
+	mu       sync.Mutex
+	nextId   int64
+	channels map[string]map[int64]func(msg *gredis.Message)
+	patterns map[string]map[int64]func(msg *gredis.Message)
+	pending  map[string]chan struct{} // 等待订阅确认的channel和pattern
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+/**
+ * 获取共享的订阅连接,第一次调用时创建
+ *
+ * return: *subscriber
+ */
+func (r *Redis) sharedSubscriber() *subscriber {
+	r.subscriberMu.Lock()
+	defer r.subscriberMu.Unlock()
+	if r.subscriber == nil {
+		s := &subscriber{
+			ps:       r.Subscribe(context.Background()),
+			channels: make(map[string]map[int64]func(msg *gredis.Message)),
+			patterns: make(map[string]map[int64]func(msg *gredis.Message)),
+			pending:  make(map[string]chan struct{}),
+			done:     make(chan struct{}),
+		}
+		var ctx context.Context
+		ctx, s.cancel = context.WithCancel(context.Background())
+		go s.listen(ctx)
+		r.subscriber = s
+	}
+	return r.subscriber
+}
+
+/**
+ * 添加监听器,channel或pattern的第一个监听器添加时订阅,收到订阅确认后返回,返回后发布的消息不会丢失
+ *
+ * param: string                     name    channel或pattern
+ * param: bool                       pattern 是否是pattern
+ * param: func(msg *gredis.Message)  f
+ * return: int64 监听器id
+ * return: error ctx在收到确认前结束时删除监听器并返回ctx.Err()
+ */
+func (s *subscriber) add(ctx context.Context, name string, pattern bool, f func(msg *gredis.Message)) (int64, error) {
+	s.mu.Lock()
+	listeners := s.listeners(pattern)
+	key := subscriptionKey(name, pattern)
+	if len(listeners[name]) == 0 {
+		confirmed := make(chan struct{})
+		s.pending[key] = confirmed
+		var err error
+		if pattern {
+			err = s.ps.PSubscribe(ctx, name)
+		} else {
+			err = s.ps.Subscribe(ctx, name)
+		}
+		if err != nil {
+			delete(s.pending, key)
+			s.mu.Unlock()
+			return 0, err
+		}
+		listeners[name] = make(map[int64]func(msg *gredis.Message))
+	}
+	s.nextId++
+	id := s.nextId
+	listeners[name][id] = f
+	// 同一个channel的其他监听器正在等待时也需要等待同一个确认
+	confirmed := s.pending[key]
+	s.mu.Unlock()
+	if confirmed == nil {
+		return id, nil
+	}
+	select {
+	case <-confirmed:
+		return id, nil
+	case <-ctx.Done():
+		_ = s.remove(context.Background(), name, pattern, id)
+		return 0, ctx.Err()
+	}
+}
+
+/**
+ * 删除监听器,ids为空时删除全部,最后一个监听器删除后取消订阅
+ *
+ * param: string    name
+ * param: bool      pattern
+ * param: ...int64  ids
+ * return: error
+ */
+func (s *subscriber) remove(ctx context.Context, name string, pattern bool, ids ...int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	listeners := s.listeners(pattern)
+	if len(listeners[name]) == 0 {
+		return nil
+	}
+	if len(ids) == 0 {
+		delete(listeners, name)
+	}
+	for _, id := range ids {
+		delete(listeners[name], id)
+	}
+	if len(listeners[name]) > 0 {
+		return nil
+	}
+	delete(listeners, name)
+	// 还在等待确认的监听器已经被删除,不需要继续等待
+	if confirmed, ok := s.pending[subscriptionKey(name, pattern)]; ok {
+		close(confirmed)
+		delete(s.pending, subscriptionKey(name, pattern))
+	}
+	if pattern {
+		return s.ps.PUnsubscribe(ctx, name)
+	}
+	return s.ps.Unsubscribe(ctx, name)
+}
+
+/**
+ * 监听器数量
+ *
+ * param: string name
+ * param: bool   pattern
+ * return: int
+ */
+func (s *subscriber) count(name string, pattern bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.listeners(pattern)[name])
+}
+
+func (s *subscriber) listeners(pattern bool) map[string]map[int64]func(msg *gredis.Message) {
+	if pattern {
+		return s.patterns
+	}
+	return s.channels
+}
+
+func (s *subscriber) listen(ctx context.Context) {
+	defer close(s.done)
+	listenPubSub(ctx, s.ps, 0, pubSubHandler{
+		onMessage:      s.dispatch,
+		onSubscription: s.confirm,
+	})
+}
+
+func (s *subscriber) confirm(sub *gredis.Subscription) {
+	var key string
+	switch sub.Kind {
+	case "subscribe":
+		key = subscriptionKey(sub.Channel, false)
+	case "psubscribe":
+		key = subscriptionKey(sub.Channel, true)
+	default:
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if confirmed, ok := s.pending[key]; ok {
+		close(confirmed)
+		delete(s.pending, key)
+	}
+}
+
+func (s *subscriber) dispatch(msg *gredis.Message) {
+	s.mu.Lock()
+	var fs []func(msg *gredis.Message)
+	if msg.Pattern != "" {
+		for _, f := range s.patterns[msg.Pattern] {
+			fs = append(fs, f)
+		}
+	} else {
+		for _, f := range s.channels[msg.Channel] {
+			fs = append(fs, f)
+		}
+	}
+	s.mu.Unlock()
+	for _, f := range fs {
+		f(msg)
+	}
+}
+
+func subscriptionKey(name string, pattern bool) string {
+	if pattern {
+		return "p:" + name
+	}
+	return "c:" + name
+}
+
+func (s *subscriber) close() {
+	s.cancel()
+	_ = s.ps.Close()
+	<-s.done
+}
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+func TestSubscriber_dispatch(t *testing.T) {
+	var got []string
+	listener := func(name string) func(msg *gredis.Message) {
+		return func(msg *gredis.Message) {
+			got = append(got, name+":"+msg.Payload)
+		}
+	}
+	s := &subscriber{
+		channels: map[string]map[int64]func(msg *gredis.Message){
+			"news": {1: listener("a"), 2: listener("b")},
+		},
+		patterns: map[string]map[int64]func(msg *gredis.Message){
+			"n*": {3: listener("p")},
+		},
+	}
+	tests := []struct {
+		name string
+		msg  *gredis.Message
+		want []string
+	}{
+		{"channel", &gredis.Message{Channel: "news", Payload: "1"}, []string{"a:1", "b:1"}},
+		{"pattern", &gredis.Message{Channel: "news", Pattern: "n*", Payload: "2"}, []string{"p:2"}},
+		{"no listener", &gredis.Message{Channel: "other", Payload: "3"}, nil},
+		{"no pattern listener", &gredis.Message{Channel: "news", Pattern: "x*", Payload: "4"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			s.dispatch(tt.msg)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dispatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriber_confirm(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending string
+		pattern bool
+		sub     *gredis.Subscription
+		want    bool
+	}{
+		{"subscribe", "news", false, &gredis.Subscription{Kind: "subscribe", Channel: "news"}, true},
+		{"psubscribe", "n*", true, &gredis.Subscription{Kind: "psubscribe", Channel: "n*"}, true},
+		{"kind mismatch", "news", true, &gredis.Subscription{Kind: "subscribe", Channel: "news"}, false},
+		{"other channel", "news", false, &gredis.Subscription{Kind: "subscribe", Channel: "sport"}, false},
+		{"unsubscribe", "news", false, &gredis.Subscription{Kind: "unsubscribe", Channel: "news"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confirmed := make(chan struct{})
+			s := &subscriber{pending: map[string]chan struct{}{subscriptionKey(tt.pending, tt.pattern): confirmed}}
+			s.confirm(tt.sub)
+			select {
+			case <-confirmed:
+				if !tt.want {
+					t.Fatal("confirm() closed an unrelated subscription")
+				}
+				if len(s.pending) != 0 {
+					t.Errorf("confirm() left %v pending", s.pending)
+				}
+			default:
+				if tt.want {
+					t.Fatal("confirm() did not close the subscription")
+				}
+			}
+		})
+	}
+}
+
+func TestSubscriber_addRemove(t *testing.T) {
+	noop := func(msg *gredis.Message) {}
+	tests := []struct {
+		name       string
+		channels   map[string]map[int64]func(msg *gredis.Message)
+		add        bool
+		remove     []int64
+		wantErr    bool
+		wantCount  int
+		wantActive bool // channel仍然保持订阅
+	}{
+		{"subscribe fails", map[string]map[int64]func(msg *gredis.Message){}, true, nil, true, 0, false},
+		{"second listener skips subscribe", map[string]map[int64]func(msg *gredis.Message){"news": {1: noop}}, true, nil, false, 2, true},
+		{"remove one of two", map[string]map[int64]func(msg *gredis.Message){"news": {1: noop, 2: noop}}, false, []int64{1}, false, 1, true},
+		{"remove last unsubscribes", map[string]map[int64]func(msg *gredis.Message){"news": {1: noop}}, false, []int64{1}, true, 0, false},
+		{"remove all", map[string]map[int64]func(msg *gredis.Message){"news": {1: noop, 2: noop}}, false, nil, true, 0, false},
+		{"remove unknown channel", map[string]map[int64]func(msg *gredis.Message){}, false, nil, false, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := &subscriber{
+				ps:       unreachableRedis().Subscribe(ctx),
+				nextId:   2,
+				channels: tt.channels,
+				patterns: make(map[string]map[int64]func(msg *gredis.Message)),
+				pending:  make(map[string]chan struct{}),
+			}
+			defer s.ps.Close()
+			var err error
+			if tt.add {
+				_, err = s.add(ctx, "news", false, noop)
+			} else {
+				err = s.remove(ctx, "news", false, tt.remove...)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := s.count("news", false); got != tt.wantCount {
+				t.Errorf("count() = %v, want %v", got, tt.wantCount)
+			}
+			if _, ok := s.channels["news"]; ok != tt.wantActive {
+				t.Errorf("channel active = %v, want %v", ok, tt.wantActive)
+			}
+			if len(s.pending) != 0 {
+				t.Errorf("pending = %v, want empty", s.pending)
+			}
+		})
+	}
+}
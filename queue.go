@@ -0,0 +1,208 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+// Queue 先进先出的分布式队列,从末尾添加,从头部取出
+type Queue[V any] struct {
+	List[V]
+}
+
+// Deque 两端都可以添加和取出的分布式双端队列
+type Deque[V any] struct {
+	Queue[V]
+}
+
+/**
+ * 获取Queue
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *Queue[V]
+ */
+func GetQueue[V any](r *Redis, name string) *Queue[V] {
+	return GetQueueWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的Queue
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *Queue[V]
+ */
+func GetQueueWithCodec[V any](r *Redis, name string, c codec.Codec) *Queue[V] {
+	return &Queue[V]{List: List[V]{object: r.newObject(name, c)}}
+}
+
+/**
+ * 获取Deque
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *Deque[V]
+ */
+func GetDeque[V any](r *Redis, name string) *Deque[V] {
+	return GetDequeWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的Deque
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *Deque[V]
+ */
+func GetDequeWithCodec[V any](r *Redis, name string, c codec.Codec) *Deque[V] {
+	return &Deque[V]{Queue: *GetQueueWithCodec[V](r, name, c)}
+}
+
+/**
+ * 在队尾添加元素
+ *
+ * param: ...V values
+ * return: int64 添加后的长度
+ * return: error
+ */
+func (q *Queue[V]) Offer(ctx context.Context, values ...V) (int64, error) {
+	return q.Add(ctx, values...)
+}
+
+/**
+ * 取出队头元素
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *Queue[V]) Poll(ctx context.Context) (V, bool, error) {
+	return q.decodeResult(q.r.LPop(ctx, q.name).Result())
+}
+
+/**
+ * 读取队头元素,不取出
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *Queue[V]) Peek(ctx context.Context) (V, bool, error) {
+	return q.Get(ctx, 0)
+}
+
+/**
+ * 取出队尾元素并添加到另一个队列的队头,集群模式下两个队列需要位于同一个slot
+ *
+ * param: string dest
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *Queue[V]) PollLastAndOfferFirstTo(ctx context.Context, dest string) (V, bool, error) {
+	dest = q.r.namespaced(dest)
+	if err := q.r.checkSameSlot(q.name, dest); err != nil {
+		var v V
+		return v, false, err
+	}
+	return q.decodeResult(q.r.RPopLPush(ctx, q.name, dest).Result())
+}
+
+/**
+ * 在头部添加元素,多个元素时最后一个位于头部
+ *
+ * param: ...V values
+ * return: int64 添加后的长度
+ * return: error
+ */
+func (d *Deque[V]) AddFirst(ctx context.Context, values ...V) (int64, error) {
+	if len(values) == 0 {
+		return d.Size(ctx)
+	}
+	items, err := encodeValues(&d.object, values)
+	if err != nil {
+		return 0, err
+	}
+	return d.r.LPush(ctx, d.name, items...).Result()
+}
+
+/**
+ * 在末尾添加元素
+ *
+ * param: ...V values
+ * return: int64 添加后的长度
+ * return: error
+ */
+func (d *Deque[V]) AddLast(ctx context.Context, values ...V) (int64, error) {
+	return d.Add(ctx, values...)
+}
+
+/**
+ * 取出头部元素
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (d *Deque[V]) PollFirst(ctx context.Context) (V, bool, error) {
+	return d.Poll(ctx)
+}
+
+/**
+ * 取出末尾元素
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (d *Deque[V]) PollLast(ctx context.Context) (V, bool, error) {
+	return d.decodeResult(d.r.RPop(ctx, d.name).Result())
+}
+
+/**
+ * 读取头部元素,不取出
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (d *Deque[V]) PeekFirst(ctx context.Context) (V, bool, error) {
+	return d.Get(ctx, 0)
+}
+
+/**
+ * 读取末尾元素,不取出
+ *
+ * return: V
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (d *Deque[V]) PeekLast(ctx context.Context) (V, bool, error) {
+	return d.Get(ctx, -1)
+}
+
+/**
+ * 作为栈使用时压入元素,等同于AddFirst
+ *
+ * param: V value
+ * return: error
+ */
+func (d *Deque[V]) Push(ctx context.Context, value V) error {
+	_, err := d.AddFirst(ctx, value)
+	return err
+}
+
+/**
+ * 作为栈使用时弹出元素,等同于PollFirst
+ *
+ * return: V
+ * return: bool 栈为空时返回false
+ * return: error
+ */
+func (d *Deque[V]) Pop(ctx context.Context) (V, bool, error) {
+	return d.PollFirst(ctx)
+}
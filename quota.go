@@ -0,0 +1,218 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+// QuotaPeriod 配额周期,按配置时区的自然时间对齐重置
+type QuotaPeriod int
+
+const (
+	QuotaPeriodMinute QuotaPeriod = iota
+	QuotaPeriodHour
+	QuotaPeriodDay
+	QuotaPeriodWeek // 周一零点重置
+	QuotaPeriodMonth
+)
+
+const (
+	QuotaPrefix = "quota:"
+
+	// KEYS 每个周期的计数key ARGV[1] 本次消耗数 ARGV[2i] 第i个周期的limit ARGV[2i+1] 第i个周期的过期时间戳(毫秒)
+	// 只有所有周期都允许时才扣减,返回 {是否允许, 第1个周期已用数, 第2个周期已用数, ...}
+	QuotaConsumeScript = `
+local n = tonumber(ARGV[1])
+local used = {}
+local allowed = 1
+for i, key in ipairs(KEYS) do
+	used[i] = tonumber(redis.call('get', key) or '0')
+	if used[i] + n > tonumber(ARGV[i * 2]) then
+		allowed = 0
+	end
+end
+if allowed == 1 then
+	for i, key in ipairs(KEYS) do
+		used[i] = redis.call('incrby', key, n)
+		redis.call('pexpireat', key, ARGV[i * 2 + 1])
+	end
+end
+table.insert(used, 1, allowed)
+return used
+`
+)
+
+var quotaConsumeScripter = gredis.NewScript(QuotaConsumeScript)
+
+var quotaPeriodNames = map[QuotaPeriod]string{
+	QuotaPeriodMinute: "minute",
+	QuotaPeriodHour:   "hour",
+	QuotaPeriodDay:    "day",
+	QuotaPeriodWeek:   "week",
+	QuotaPeriodMonth:  "month",
+}
+
+// QuotaWindow 一个周期的配额
+type QuotaWindow struct {
+	Period QuotaPeriod
+	Limit  int64
+}
+
+// QuotaUsage 一个周期当前的使用情况
+type QuotaUsage struct {
+	Period    QuotaPeriod
+	Limit     int64
+	Used      int64
+	Remaining int64
+	Start     time.Time
+	End       time.Time // 重置时间
+}
+
+// QuotaResult 消耗配额的结果
+type QuotaResult struct {
+	Allowed bool
+	Usages  []QuotaUsage
+}
+
+// Quota 多周期配额,同时检查并扣减所有周期
+type Quota struct {
+	r       *Redis
+	name    string
+	loc     *time.Location
+	windows []QuotaWindow
+}
+
+/**
+ * 获取配额对象
+ *
+ * param: string         name
+ * param: *time.Location loc     周期对齐使用的时区,nil时使用UTC
+ * param: ...QuotaWindow windows
+ * return: *Quota
+ */
+func (r *Redis) GetQuota(name string, loc *time.Location, windows ...QuotaWindow) *Quota {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Quota{r: r, name: r.namespaced(QuotaPrefix + name), loc: loc, windows: windows}
+}
+
+/**
+ * 消耗n个配额,任一周期不足时都不扣减
+ *
+ * param: int64 n
+ * return: *QuotaResult
+ * return: error
+ */
+func (q *Quota) Consume(ctx context.Context, n int64) (*QuotaResult, error) {
+	if n <= 0 {
+		return nil, ErrInvalidPermits
+	}
+	usages := q.usages(time.Now())
+	keys := make([]string, len(usages))
+	args := make([]interface{}, 0, len(usages)*2+1)
+	args = append(args, n)
+	for i, u := range usages {
+		keys[i] = q.key(u)
+		args = append(args, u.Limit, u.End.UnixNano()/int64(time.Millisecond))
+	}
+	res, err := int64Slice(quotaConsumeScripter.Run(ctx, q.r, keys, args...))
+	if err != nil {
+		return nil, err
+	}
+	for i := range usages {
+		usages[i].setUsed(res[i+1])
+	}
+	return &QuotaResult{Allowed: res[0] == 1, Usages: usages}, nil
+}
+
+/**
+ * 当前各周期的使用情况
+ *
+ * return: []QuotaUsage
+ * return: error
+ */
+func (q *Quota) Usage(ctx context.Context) ([]QuotaUsage, error) {
+	usages := q.usages(time.Now())
+	keys := make([]string, len(usages))
+	for i, u := range usages {
+		keys[i] = q.key(u)
+	}
+	values, err := q.r.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		var used int64
+		if s, ok := v.(string); ok {
+			if used, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		usages[i].setUsed(used)
+	}
+	return usages, nil
+}
+
+func (q *Quota) usages(now time.Time) []QuotaUsage {
+	usages := make([]QuotaUsage, len(q.windows))
+	for i, w := range q.windows {
+		start, end := w.Period.Bounds(now, q.loc)
+		usages[i] = QuotaUsage{Period: w.Period, Limit: w.Limit, Remaining: w.Limit, Start: start, End: end}
+	}
+	return usages
+}
+
+func (q *Quota) key(u QuotaUsage) string {
+	return suffixName(q.name, u.Period.String()+":"+strconv.FormatInt(u.Start.Unix(), 10))
+}
+
+func (u *QuotaUsage) setUsed(used int64) {
+	u.Used = used
+	u.Remaining = u.Limit - used
+	if u.Remaining < 0 {
+		u.Remaining = 0
+	}
+}
+
+/**
+ * 计算t所在周期的起止时间
+ *
+ * param: time.Time      t
+ * param: *time.Location loc
+ * return: time.Time start
+ * return: time.Time end
+ */
+func (p QuotaPeriod) Bounds(t time.Time, loc *time.Location) (start, end time.Time) {
+	t = t.In(loc)
+	y, m, d := t.Date()
+	switch p {
+	case QuotaPeriodMinute:
+		start = time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc)
+		end = start.Add(time.Minute)
+	case QuotaPeriodHour:
+		start = time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+		end = start.Add(time.Hour)
+	case QuotaPeriodDay:
+		start = time.Date(y, m, d, 0, 0, 0, 0, loc)
+		end = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	case QuotaPeriodWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		start = time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+		end = time.Date(y, m, d-offset+7, 0, 0, 0, 0, loc)
+	case QuotaPeriodMonth:
+		start = time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		end = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+	}
+	return start, end
+}
+
+func (p QuotaPeriod) String() string {
+	if name, ok := quotaPeriodNames[p]; ok {
+		return name
+	}
+	return "period" + strconv.Itoa(int(p))
+}
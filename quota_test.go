@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuotaPeriod_Bounds(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	now := time.Date(2021, 5, 31, 17, 30, 15, 0, time.UTC) // 2021-06-01 01:30:15 CST, 周二
+	tests := []struct {
+		name      string
+		period    QuotaPeriod
+		loc       *time.Location
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"minute", QuotaPeriodMinute, shanghai, time.Date(2021, 6, 1, 1, 30, 0, 0, shanghai), time.Date(2021, 6, 1, 1, 31, 0, 0, shanghai)},
+		{"hour", QuotaPeriodHour, shanghai, time.Date(2021, 6, 1, 1, 0, 0, 0, shanghai), time.Date(2021, 6, 1, 2, 0, 0, 0, shanghai)},
+		{"day-utc", QuotaPeriodDay, time.UTC, time.Date(2021, 5, 31, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"day-cst", QuotaPeriodDay, shanghai, time.Date(2021, 6, 1, 0, 0, 0, 0, shanghai), time.Date(2021, 6, 2, 0, 0, 0, 0, shanghai)},
+		{"week", QuotaPeriodWeek, shanghai, time.Date(2021, 5, 31, 0, 0, 0, 0, shanghai), time.Date(2021, 6, 7, 0, 0, 0, 0, shanghai)},
+		{"month-utc", QuotaPeriodMonth, time.UTC, time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"month-cst", QuotaPeriodMonth, shanghai, time.Date(2021, 6, 1, 0, 0, 0, 0, shanghai), time.Date(2021, 7, 1, 0, 0, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.period.Bounds(now, tt.loc)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("Bounds() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
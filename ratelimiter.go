@@ -0,0 +1,223 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+// RateType 限流模式
+type RateType int
+
+const (
+	RateTypeOverall   RateType = iota // 所有客户端共享同一个令牌桶
+	RateTypePerClient                 // 每个客户端独立一个令牌桶
+)
+
+const (
+	RateLimiterPrefix = "ratelimiter:"
+
+	// KEYS[1] 配置 KEYS[2] 全局令牌桶 KEYS[3] 当前客户端令牌桶
+	// ARGV[1] 需要获取的令牌数,为0时只返回可用令牌数
+	// 返回 {是否获取成功, 剩余令牌数, 需要等待的毫秒数, 令牌桶补满的毫秒数, 速率}, 未初始化返回 {-1}, 超出速率返回 {-2}
+	RateLimiterAcquireScript = `
+redis.replicate_commands()
+local conf = redis.call('hmget', KEYS[1], 'rate', 'interval', 'type')
+if conf[1] == false or conf[2] == false then
+	return {-1}
+end
+local rate = tonumber(conf[1])
+local interval = tonumber(conf[2])
+local key = KEYS[2]
+if conf[3] == '1' then
+	key = KEYS[3]
+end
+local permits = tonumber(ARGV[1])
+if permits > rate then
+	return {-2}
+end
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local tokens = rate
+local state = redis.call('hmget', key, 'tokens', 'ts')
+if state[1] ~= false and state[2] ~= false then
+	tokens = math.min(rate, tonumber(state[1]) + math.max(0, now - tonumber(state[2])) * rate / interval)
+end
+local function result(ok, wait)
+	return {ok, math.floor(tokens), wait, math.ceil((rate - tokens) * interval / rate), rate}
+end
+if permits == 0 then
+	return result(1, 0)
+end
+if tokens >= permits then
+	tokens = tokens - permits
+	redis.call('hmset', key, 'tokens', tostring(tokens), 'ts', now)
+	redis.call('pexpire', key, interval)
+	return result(1, 0)
+end
+return result(0, math.ceil((permits - tokens) * interval / rate))
+`
+	RateLimiterSetRateScript = "if redis.call('hsetnx', KEYS[1], 'rate', ARGV[1]) == 1 then redis.call('hmset', KEYS[1], 'interval', ARGV[2], 'type', ARGV[3]) return 1 end return 0"
+)
+
+var (
+	rateLimiterAcquireScripter = gredis.NewScript(RateLimiterAcquireScript)
+	rateLimiterSetRateScripter = gredis.NewScript(RateLimiterSetRateScript)
+)
+
+// RateLimiter 基于令牌桶的分布式限流器
+type RateLimiter struct {
+	r    *Redis
+	name string
+}
+
+/**
+ * 获取限流器
+ *
+ * param: string name
+ * return: *RateLimiter
+ */
+func (r *Redis) GetRateLimiter(name string) *RateLimiter {
+	return &RateLimiter{r: r, name: r.namespaced(RateLimiterPrefix + name)}
+}
+
+/**
+ * 设置速率,已经设置过时不会覆盖
+ *
+ * param: RateType      rateType
+ * param: int64         rate     每个interval内产生的令牌数
+ * param: time.Duration interval
+ * return: bool 是否设置成功
+ * return: error
+ */
+func (l *RateLimiter) TrySetRate(ctx context.Context, rateType RateType, rate int64, interval time.Duration) (bool, error) {
+	if rate <= 0 || interval < time.Millisecond {
+		return false, ErrInvalidPermits
+	}
+	res, err := rateLimiterSetRateScripter.Run(ctx, l.r, []string{l.name}, rate, interval.Milliseconds(), int(rateType)).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+/**
+ * 尝试获取令牌,不阻塞
+ *
+ * param: int64 permits
+ * return: bool
+ * return: error
+ */
+func (l *RateLimiter) TryAcquire(ctx context.Context, permits int64) (bool, error) {
+	if permits <= 0 {
+		return false, ErrInvalidPermits
+	}
+	ok, _, _, err := l.tryAcquire(ctx, permits)
+	return ok, err
+}
+
+/**
+ * 尝试获取一个令牌,返回结果可直接用于设置限流响应头
+ *
+ * return: *RateLimitResult Limit为速率,ResetAfter为令牌桶补满的时间
+ * return: error
+ */
+func (l *RateLimiter) Allow(ctx context.Context) (*RateLimitResult, error) {
+	return l.AllowN(ctx, 1)
+}
+
+/**
+ * 尝试获取permits个令牌,不阻塞
+ *
+ * param: int64 permits
+ * return: *RateLimitResult
+ * return: error
+ */
+func (l *RateLimiter) AllowN(ctx context.Context, permits int64) (*RateLimitResult, error) {
+	if permits <= 0 {
+		return nil, ErrInvalidPermits
+	}
+	res, err := l.acquire(ctx, permits)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimitResult{
+		Allowed:    res[0] == 1,
+		Limit:      res[4],
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
+
+/**
+ * 获取令牌,令牌不足时阻塞直到获取成功或ctx结束
+ *
+ * param: int64 permits
+ * return: error
+ */
+func (l *RateLimiter) Acquire(ctx context.Context, permits int64) error {
+	if permits <= 0 {
+		return ErrInvalidPermits
+	}
+	for {
+		ok, _, wait, err := l.tryAcquire(ctx, permits)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+/**
+ * 当前可用令牌数
+ *
+ * return: int64
+ * return: error
+ */
+func (l *RateLimiter) AvailablePermits(ctx context.Context) (int64, error) {
+	_, available, _, err := l.tryAcquire(ctx, 0)
+	return available, err
+}
+
+/**
+ * 执行获取令牌脚本
+ *
+ * param: int64 permits
+ * return: bool          是否获取成功
+ * return: int64         剩余令牌数
+ * return: time.Duration 获取失败时需要等待的时间
+ * return: error
+ */
+func (l *RateLimiter) tryAcquire(ctx context.Context, permits int64) (bool, int64, time.Duration, error) {
+	res, err := l.acquire(ctx, permits)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return res[0] == 1, res[1], time.Duration(res[2]) * time.Millisecond, nil
+}
+
+func (l *RateLimiter) acquire(ctx context.Context, permits int64) ([]int64, error) {
+	keys := []string{l.name, suffixName(l.name, "value"), suffixName(l.name, "value:"+l.r.ID())}
+	res, err := int64Slice(rateLimiterAcquireScripter.Run(ctx, l.r, keys, permits))
+	if err != nil {
+		return nil, err
+	}
+	switch res[0] {
+	case -1:
+		return nil, ErrRateLimiterNotInit
+	case -2:
+		return nil, ErrPermitsExceedRate
+	}
+	return res, nil
+}
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_invalidArgs(t *testing.T) {
+	ctx := context.Background()
+	l := unreachableRedis().GetRateLimiter("test-limiter")
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"TrySetRate zero rate", func() error { _, err := l.TrySetRate(ctx, RateTypeOverall, 0, time.Second); return err }},
+		{"TrySetRate short interval", func() error { _, err := l.TrySetRate(ctx, RateTypeOverall, 1, time.Microsecond); return err }},
+		{"TryAcquire zero", func() error { _, err := l.TryAcquire(ctx, 0); return err }},
+		{"AllowN negative", func() error { _, err := l.AllowN(ctx, -1); return err }},
+		{"Acquire zero", func() error { return l.Acquire(ctx, 0) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 参数错误在访问redis之前返回
+			if err := tt.call(); err != ErrInvalidPermits {
+				t.Errorf("error = %v, want %v", err, ErrInvalidPermits)
+			}
+		})
+	}
+}
@@ -2,18 +2,31 @@ package redis
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ainiaa/catutil/v2/rediscat"
 	gredis "github.com/go-redis/redis/v8"
+	"github.com/hashicorp/go-uuid"
 
+	"github.com/ainiaa/go-redisson/codec"
 	"github.com/ainiaa/go-redisson/conf"
 )
 
 var redisCluster = &Redis{}
 var redisClients = make(map[string]*Redis, 2)
 
+// 在脚本中读取服务端的毫秒时间戳,保存为now,多个实例之间的时钟偏差不影响过期判断
+// 脚本在TIME之后执行写命令需要开启命令复制
+const serverNowScript = `
+redis.replicate_commands()
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+`
+
 const (
 	ConnTypeCluster  = "cluster"
 	ConnTypeAlone    = "alone"
@@ -23,23 +36,99 @@ const (
 type Redis struct {
 	gredis.UniversalClient
 	*conf.Config
+	id    string
+	codec codec.Codec
+
+	blockingMu sync.Mutex
+	blocking   gredis.UniversalClient
+
+	subscriberMu sync.Mutex
+	subscriber   *subscriber
 }
 
-func New(ctx context.Context, c *conf.Config) (*Redis, error) {
+func New(ctx context.Context, c *conf.Config) (r *Redis, err error) {
+	cc, err := codec.New(c.Codec, c.Compression)
+	if err != nil {
+		return nil, err
+	}
 	switch c.ConnType {
 	case ConnTypeCluster:
-		return newClusterClient(ctx, c)
+		r, err = newClusterClient(ctx, c)
 	case ConnTypeAlone:
-		return newAloneClient(ctx, c)
+		r, err = newAloneClient(ctx, c)
 	case ConnTypeSentinel:
-		return newSentinel(ctx, c)
+		r, err = newSentinel(ctx, c)
 	default:
 		return nil, ErrConnTypeUnknown
 	}
+	if err != nil {
+		return nil, err
+	}
+	r.id, _ = uuid.GenerateUUID()
+	r.codec = cc
+	return r, nil
+}
+
+/**
+ * 客户端唯一标识,用于区分同一个redis上的不同客户端实例
+ *
+ * return: string
+ */
+func (r *Redis) ID() string {
+	return r.id
+}
+
+/**
+ * 客户端默认的codec,对象没有单独指定codec时使用
+ *
+ * return: codec.Codec
+ */
+func (r *Redis) DefaultCodec() codec.Codec {
+	if r.codec == nil {
+		return codec.JSON
+	}
+	return r.codec
+}
+
+/**
+ * 给对象名加上客户端命名空间前缀
+ *
+ * param: string name
+ * return: string
+ */
+func (r *Redis) namespaced(name string) string {
+	if r.Config == nil || r.Namespace == "" {
+		return name
+	}
+	return r.Namespace + ":" + name
+}
+
+/**
+ * 生成与name处于同一slot的关联key
+ *
+ * param: string name
+ * param: string suffix
+ * return: string
+ */
+func suffixName(name, suffix string) string {
+	if strings.Contains(name, "{") {
+		return name + ":" + suffix
+	}
+	return "{" + name + "}:" + suffix
 }
 
 func newClusterClient(ctx context.Context, c *conf.Config) (*Redis, error) {
-	client := gredis.NewClusterClient(&gredis.ClusterOptions{
+	client := gredis.NewClusterClient(clusterOptions(c))
+	pong, err := client.Ping(ctx).Result()
+	if pong != "PONG" || err != nil {
+		return nil, ErrPing.SubError(err)
+	}
+	client.AddHook(rediscat.RedisTraceHook{})
+	return &Redis{UniversalClient: client, Config: c}, nil
+}
+
+func clusterOptions(c *conf.Config) *gredis.ClusterOptions {
+	return &gredis.ClusterOptions{
 		Addrs:              c.Cluster.Addrs,
 		MaxRedirects:       c.Cluster.MaxRedirects,
 		ReadOnly:           c.Cluster.ReadOnly,
@@ -59,7 +148,11 @@ func newClusterClient(ctx context.Context, c *conf.Config) (*Redis, error) {
 		PoolTimeout:        time.Duration(c.Cluster.PoolTimeout) * time.Millisecond,
 		IdleTimeout:        time.Duration(c.Cluster.IdleTimeout) * time.Millisecond,
 		IdleCheckFrequency: time.Duration(c.Cluster.IdleCheckFrequency) * time.Millisecond,
-	})
+	}
+}
+
+func newAloneClient(ctx context.Context, c *conf.Config) (*Redis, error) {
+	client := gredis.NewClient(aloneOptions(c))
 	pong, err := client.Ping(ctx).Result()
 	if pong != "PONG" || err != nil {
 		return nil, ErrPing.SubError(err)
@@ -68,8 +161,8 @@ func newClusterClient(ctx context.Context, c *conf.Config) (*Redis, error) {
 	return &Redis{UniversalClient: client, Config: c}, nil
 }
 
-func newAloneClient(ctx context.Context, c *conf.Config) (*Redis, error) {
-	client := gredis.NewClient(&gredis.Options{
+func aloneOptions(c *conf.Config) *gredis.Options {
+	return &gredis.Options{
 		Network:            c.Alone.Network,
 		Addr:               c.Alone.Addr,
 		Username:           c.Alone.Username,
@@ -87,7 +180,11 @@ func newAloneClient(ctx context.Context, c *conf.Config) (*Redis, error) {
 		PoolTimeout:        time.Duration(c.Alone.PoolTimeout) * time.Millisecond,
 		IdleTimeout:        time.Duration(c.Alone.IdleTimeout) * time.Millisecond,
 		IdleCheckFrequency: time.Duration(c.Alone.IdleCheckFrequency) * time.Millisecond,
-	})
+	}
+}
+
+func newSentinel(ctx context.Context, c *conf.Config) (*Redis, error) {
+	client := gredis.NewRing(sentinelOptions(c))
 	pong, err := client.Ping(ctx).Result()
 	if pong != "PONG" || err != nil {
 		return nil, ErrPing.SubError(err)
@@ -96,8 +193,8 @@ func newAloneClient(ctx context.Context, c *conf.Config) (*Redis, error) {
 	return &Redis{UniversalClient: client, Config: c}, nil
 }
 
-func newSentinel(ctx context.Context, c *conf.Config) (*Redis, error) {
-	client := gredis.NewRing(&gredis.RingOptions{
+func sentinelOptions(c *conf.Config) *gredis.RingOptions {
+	return &gredis.RingOptions{
 		Addrs:              c.Sentinel.Addrs,
 		HeartbeatFrequency: time.Duration(c.Sentinel.HeartbeatFrequency) * time.Millisecond,
 		DB:                 c.Sentinel.DB,
@@ -114,15 +211,58 @@ func newSentinel(ctx context.Context, c *conf.Config) (*Redis, error) {
 		PoolTimeout:        time.Duration(c.Sentinel.PoolTimeout) * time.Millisecond,
 		IdleTimeout:        time.Duration(c.Sentinel.IdleTimeout) * time.Millisecond,
 		IdleCheckFrequency: time.Duration(c.Sentinel.IdleCheckFrequency) * time.Millisecond,
-	})
-	pong, err := client.Ping(ctx).Result()
-	if pong != "PONG" || err != nil {
-		return nil, ErrPing.SubError(err)
+	}
+}
+
+/**
+ * 阻塞命令使用的独立客户端,第一次使用时创建,避免长时间阻塞的命令占满默认连接池
+ *
+ * return: gredis.UniversalClient
+ */
+func (r *Redis) blockingClient() gredis.UniversalClient {
+	r.blockingMu.Lock()
+	defer r.blockingMu.Unlock()
+	if r.blocking != nil {
+		return r.blocking
+	}
+	var client gredis.UniversalClient
+	switch {
+	case r.Config == nil:
+		return r.UniversalClient
+	case r.ConnType == ConnTypeCluster:
+		client = gredis.NewClusterClient(clusterOptions(r.Config))
+	case r.ConnType == ConnTypeAlone:
+		client = gredis.NewClient(aloneOptions(r.Config))
+	case r.ConnType == ConnTypeSentinel:
+		client = gredis.NewRing(sentinelOptions(r.Config))
+	default:
+		return r.UniversalClient
 	}
 	client.AddHook(rediscat.RedisTraceHook{})
-	return &Redis{UniversalClient: client, Config: c}, nil
+	r.blocking = client
+	return client
 }
 
+/**
+ * 关闭客户端,包括阻塞命令使用的独立客户端和共享的订阅连接
+ *
+ * return: error
+ */
+func (r *Redis) Close() error {
+	r.subscriberMu.Lock()
+	if r.subscriber != nil {
+		r.subscriber.close()
+		r.subscriber = nil
+	}
+	r.subscriberMu.Unlock()
+	r.blockingMu.Lock()
+	if r.blocking != nil {
+		_ = r.blocking.Close()
+		r.blocking = nil
+	}
+	r.blockingMu.Unlock()
+	return r.UniversalClient.Close()
+}
 
 func InitOnceRedis(ctx context.Context, c *conf.Config) (err error) {
 	var once sync.Once
@@ -155,3 +295,65 @@ func GetRedis() *Redis {
 func IsRedisReady() bool {
 	return redisCluster == nil || redisCluster == (&Redis{})
 }
+
+/**
+ * 将脚本返回的数组转换为[]int64
+ *
+ * param: *gredis.Cmd cmd
+ * return: []int64
+ * return: error
+ */
+func int64Slice(cmd *gredis.Cmd) ([]int64, error) {
+	val, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+	items, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected script result type %T", val)
+	}
+	res := make([]int64, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case int64:
+			res[i] = v
+		case string:
+			if res[i], err = strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("redis: unexpected script result element type %T", item)
+		}
+	}
+	return res, nil
+}
+
+/**
+ * 将脚本返回的数组转换为[]string
+ *
+ * param: *gredis.Cmd cmd
+ * return: []string
+ * return: error
+ */
+func stringSlice(cmd *gredis.Cmd) ([]string, error) {
+	val, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+	items, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected script result type %T", val)
+	}
+	res := make([]string, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case string:
+			res[i] = v
+		case int64:
+			res[i] = strconv.FormatInt(v, 10)
+		default:
+			return nil, fmt.Errorf("redis: unexpected script result element type %T", item)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ainiaa/go-redisson/conf"
+)
+
+// 连接REDIS_ADDR(默认127.0.0.1:6379)上的redis,key以测试名作为命名空间,redis不可用时跳过测试
+func testRedis(t *testing.T) *Redis {
+	t.Helper()
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	c := conf.Config{
+		ConnType:  ConnTypeAlone,
+		Namespace: "test:" + t.Name(),
+		Alone: conf.AloneConfig{
+			Addr:        addr,
+			Password:    os.Getenv("REDIS_PASSWORD"),
+			DialTimeout: 1000,
+		},
+	}
+	r, err := New(context.Background(), &c)
+	if err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = r.Close()
+	})
+	return r
+}
+
+func TestRedis_namespaced(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *conf.Config
+		key    string
+		want   string
+	}{
+		{"no config", nil, "counter", "counter"},
+		{"no namespace", &conf.Config{}, "counter", "counter"},
+		{"namespace", &conf.Config{Namespace: "app"}, "counter", "app:counter"},
+		{"hash tag", &conf.Config{Namespace: "app"}, "{counter}", "app:{counter}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Redis{Config: tt.config}
+			if got := r.namespaced(tt.key); got != tt.want {
+				t.Errorf("namespaced() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuffixName(t *testing.T) {
+	tests := []struct {
+		name   string
+		suffix string
+		want   string
+	}{
+		{"queue", "permits", "{queue}:permits"},
+		{"app:queue", "permits", "{app:queue}:permits"},
+		{"{queue}", "permits", "{queue}:permits"},
+		{"app:{queue}", "delayed", "app:{queue}:delayed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suffixName(tt.name, tt.suffix)
+			if got != tt.want {
+				t.Errorf("suffixName() = %v, want %v", got, tt.want)
+			}
+			if keySlot(got) != keySlot(tt.name) {
+				t.Errorf("suffixName() = %v is not in the slot of %v", got, tt.name)
+			}
+		})
+	}
+}
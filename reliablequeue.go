@@ -0,0 +1,390 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	DefaultVisibilityTimeout = 30 * time.Second
+
+	// KEYS[1] 待消费队列 KEYS[2] 消息内容 ARGV 依次为消息id和内容
+	ReliableQueueOfferScript = `
+for i = 1, #ARGV, 2 do
+	redis.call('hset', KEYS[2], ARGV[i], ARGV[i + 1])
+	redis.call('rpush', KEYS[1], ARGV[i])
+end
+return redis.call('llen', KEYS[1])
+`
+	// KEYS[1] 待消费队列 KEYS[2] 消息内容 KEYS[3] 消费中 KEYS[4] 投递次数 KEYS[5] 延迟队列 KEYS[6] 投递凭证
+	// ARGV[1] 可见性超时(毫秒) ARGV[2] 本次投递的凭证
+	// 返回消息id、内容和投递次数
+	ReliableQueuePollScript = serverNowScript + reliableQueuePromoteScript + `
+while true do
+	local id = redis.call('lpop', KEYS[1])
+	if id == false then
+		return nil
+	end
+	local payload = redis.call('hget', KEYS[2], id)
+	if payload ~= false then
+		redis.call('zadd', KEYS[3], now + tonumber(ARGV[1]), id)
+		redis.call('hset', KEYS[6], id, ARGV[2])
+		return {id, payload, redis.call('hincrby', KEYS[4], id, 1)}
+	end
+end
+`
+	// 凭证与最近一次投递不一致时说明消息已经超时重新投递,旧的消费者不能再确认
+	// KEYS[1] 消息内容 KEYS[2] 消费中 KEYS[3] 投递次数 KEYS[4] 投递凭证 ARGV[1] 消息id ARGV[2] 凭证
+	ReliableQueueAckScript = `
+if redis.call('hget', KEYS[4], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call('zrem', KEYS[2], ARGV[1])
+redis.call('hdel', KEYS[1], ARGV[1])
+redis.call('hdel', KEYS[3], ARGV[1])
+redis.call('hdel', KEYS[4], ARGV[1])
+return 1
+`
+	// KEYS[1] 消费中 KEYS[2] 投递凭证 ARGV[1] 可见性超时(毫秒) ARGV[2] 消息id ARGV[3] 凭证
+	ReliableQueueExtendScript = serverNowScript + `
+if redis.call('hget', KEYS[2], ARGV[2]) ~= ARGV[3] then
+	return 0
+end
+redis.call('zadd', KEYS[1], now + tonumber(ARGV[1]), ARGV[2])
+return 1
+`
+	// KEYS同 ReliableQueuePollScript, KEYS[7] 死信队列
+	// ARGV[1] 最大投递次数 ARGV[2] 重新投递前的延迟(毫秒) ARGV[3] 消息id ARGV[4] 凭证
+	ReliableQueueNackScript = serverNowScript + reliableQueueRequeueFunc + `
+if redis.call('hget', KEYS[6], ARGV[3]) ~= ARGV[4] then
+	return 0
+end
+redis.call('zrem', KEYS[3], ARGV[3])
+requeue(ARGV[3], now + tonumber(ARGV[2]))
+return 1
+`
+	// KEYS同 ReliableQueueNackScript ARGV[1] 最大投递次数
+	// 返回重新入队和进入死信队列的消息数量
+	ReliableQueueReapScript = serverNowScript + reliableQueueRequeueFunc + reliableQueuePromoteScript + `
+local expired = redis.call('zrangebyscore', KEYS[3], '-inf', now, 'limit', 0, 100)
+for _, id in ipairs(expired) do
+	redis.call('zrem', KEYS[3], id)
+	requeue(id, now)
+end
+return #expired
+`
+
+	// 把到期的延迟消息移动到待消费队列,每次最多100条,避免单个脚本执行时间过长,需要放在 serverNowScript 之后
+	reliableQueuePromoteScript = `
+local due = redis.call('zrangebyscore', KEYS[5], '-inf', now, 'limit', 0, 100)
+for _, id in ipairs(due) do
+	redis.call('zrem', KEYS[5], id)
+	redis.call('rpush', KEYS[1], id)
+end
+`
+	// 投递次数达到上限时移动到死信队列,否则重新入队,readyAt晚于当前时间时先进入延迟队列
+	// 重新入队时删除凭证,本次投递的消费者不能再确认,需要放在 serverNowScript 之后,ARGV[1]为最大投递次数
+	reliableQueueRequeueFunc = `
+local function requeue(id, readyAt)
+	redis.call('hdel', KEYS[6], id)
+	local max = tonumber(ARGV[1])
+	if max > 0 and tonumber(redis.call('hget', KEYS[4], id) or '0') >= max then
+		local payload = redis.call('hget', KEYS[2], id)
+		redis.call('hdel', KEYS[2], id)
+		redis.call('hdel', KEYS[4], id)
+		if payload ~= false then
+			redis.call('rpush', KEYS[7], payload)
+		end
+	elseif readyAt > now then
+		redis.call('zadd', KEYS[5], readyAt, id)
+	else
+		redis.call('rpush', KEYS[1], id)
+	end
+end
+`
+)
+
+var (
+	reliableQueueOfferScripter  = gredis.NewScript(ReliableQueueOfferScript)
+	reliableQueuePollScripter   = gredis.NewScript(ReliableQueuePollScript)
+	reliableQueueAckScripter    = gredis.NewScript(ReliableQueueAckScript)
+	reliableQueueExtendScripter = gredis.NewScript(ReliableQueueExtendScript)
+	reliableQueueNackScripter   = gredis.NewScript(ReliableQueueNackScript)
+	reliableQueueReapScripter   = gredis.NewScript(ReliableQueueReapScript)
+)
+
+// ReliableQueueOptions ReliableQueue的配置
+type ReliableQueueOptions struct {
+	VisibilityTimeout time.Duration // 取出后多久没有确认就重新投递,0时使用 DefaultVisibilityTimeout
+	MaxDeliveries     int64         // 最大投递次数,超过后进入死信队列,0时不限制
+}
+
+// ReliableMessage 从ReliableQueue取出的消息
+type ReliableMessage[V any] struct {
+	ID       string
+	Receipt  string // 本次投递的凭证,Ack、Nack和ExtendVisibility时需要提供
+	Value    V
+	Attempts int64 // 包括本次在内的投递次数
+}
+
+// ReliableQueue 消息需要确认的队列,取出的消息在可见性超时前没有确认时由清理任务重新投递
+// 消息至少投递一次,消费者需要自行保证幂等
+type ReliableQueue[V any] struct {
+	object
+	opts     ReliableQueueOptions
+	reaper   *evictionTask
+	items    string
+	inflight string
+	attempts string
+	delayed  string
+	receipts string
+	dlq      string
+}
+
+/**
+ * 获取ReliableQueue
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *ReliableQueue[V]
+ */
+func GetReliableQueue[V any](r *Redis, name string) *ReliableQueue[V] {
+	return GetReliableQueueWithOptions[V](r, name, nil, ReliableQueueOptions{})
+}
+
+/**
+ * 获取使用指定codec的ReliableQueue
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *ReliableQueue[V]
+ */
+func GetReliableQueueWithCodec[V any](r *Redis, name string, c codec.Codec) *ReliableQueue[V] {
+	return GetReliableQueueWithOptions[V](r, name, c, ReliableQueueOptions{})
+}
+
+/**
+ * 获取指定配置的ReliableQueue
+ *
+ * param: *Redis               r
+ * param: string               name
+ * param: codec.Codec          c    为nil时使用客户端默认的codec
+ * param: ReliableQueueOptions opts
+ * return: *ReliableQueue[V]
+ */
+func GetReliableQueueWithOptions[V any](r *Redis, name string, c codec.Codec, opts ReliableQueueOptions) *ReliableQueue[V] {
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = DefaultVisibilityTimeout
+	}
+	o := r.newObject(name, c)
+	return &ReliableQueue[V]{
+		object:   o,
+		opts:     opts,
+		items:    suffixName(o.name, "items"),
+		inflight: suffixName(o.name, "inflight"),
+		attempts: suffixName(o.name, "attempts"),
+		delayed:  suffixName(o.name, "delayed"),
+		receipts: suffixName(o.name, "receipts"),
+		dlq:      suffixName(o.name, "dlq"),
+	}
+}
+
+/**
+ * 启动后台任务,定期重新投递可见性超时的消息,多个实例同时启动时通过分布式锁保证只有一个实例在执行
+ *
+ * param: time.Duration interval
+ */
+func (q *ReliableQueue[V]) StartReaper(interval time.Duration) {
+	if q.reaper != nil {
+		return
+	}
+	q.reaper = q.r.startEviction(suffixName(q.name, "reaper"), interval, func(ctx context.Context) error {
+		_, err := q.Reap(ctx)
+		return err
+	})
+}
+
+/**
+ * 停止后台任务
+ */
+func (q *ReliableQueue[V]) StopReaper() {
+	q.reaper.close()
+}
+
+/**
+ * 在队尾添加消息
+ *
+ * param: ...V values
+ * return: []string 消息id
+ * return: error
+ */
+func (q *ReliableQueue[V]) Offer(ctx context.Context, values ...V) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(values))
+	args := make([]interface{}, 0, len(values)*2)
+	for i, v := range values {
+		item, err := q.encode(v)
+		if err != nil {
+			return nil, err
+		}
+		if ids[i], err = uuid.GenerateUUID(); err != nil {
+			return nil, err
+		}
+		args = append(args, ids[i], item)
+	}
+	err := reliableQueueOfferScripter.Run(ctx, q.r, []string{q.name, q.items}, args...).Err()
+	return ids, err
+}
+
+/**
+ * 取出队头消息,消息在可见性超时前需要通过Ack确认,每次投递生成新的凭证
+ *
+ * return: ReliableMessage[V]
+ * return: bool 队列为空时返回false
+ * return: error
+ */
+func (q *ReliableQueue[V]) Poll(ctx context.Context) (ReliableMessage[V], bool, error) {
+	var msg ReliableMessage[V]
+	receipt, err := uuid.GenerateUUID()
+	if err != nil {
+		return msg, false, err
+	}
+	res, err := stringSlice(reliableQueuePollScripter.Run(ctx, q.r, q.keys(), q.opts.VisibilityTimeout.Milliseconds(), receipt))
+	if err == gredis.Nil {
+		return msg, false, nil
+	}
+	if err != nil {
+		return msg, false, err
+	}
+	msg.ID, msg.Receipt = res[0], receipt
+	if msg.Attempts, err = strconv.ParseInt(res[2], 10, 64); err != nil {
+		return msg, false, err
+	}
+	msg.Value, err = decodeAs[V](q.codec, res[1])
+	return msg, err == nil, err
+}
+
+/**
+ * 确认消息已经处理
+ *
+ * param: string id
+ * param: string receipt Poll返回的投递凭证
+ * return: bool 消息不在消费中或已经重新投递时返回false
+ * return: error
+ */
+func (q *ReliableQueue[V]) Ack(ctx context.Context, id, receipt string) (bool, error) {
+	n, err := reliableQueueAckScripter.Run(ctx, q.r, []string{q.items, q.inflight, q.attempts, q.receipts}, id, receipt).Int()
+	return n == 1, err
+}
+
+/**
+ * 处理失败,延迟后重新投递,投递次数达到上限时进入死信队列
+ *
+ * param: string        id
+ * param: string        receipt Poll返回的投递凭证
+ * param: time.Duration delay   为0时立即重新投递
+ * return: bool 消息不在消费中或已经重新投递时返回false
+ * return: error
+ */
+func (q *ReliableQueue[V]) Nack(ctx context.Context, id, receipt string, delay time.Duration) (bool, error) {
+	n, err := reliableQueueNackScripter.Run(ctx, q.r, q.requeueKeys(), q.opts.MaxDeliveries, delay.Milliseconds(), id, receipt).Int()
+	return n == 1, err
+}
+
+/**
+ * 延长消费中消息的可见性超时,从当前时间开始计算
+ *
+ * param: string        id
+ * param: string        receipt Poll返回的投递凭证
+ * param: time.Duration timeout
+ * return: bool 消息不在消费中或已经重新投递时返回false
+ * return: error
+ */
+func (q *ReliableQueue[V]) ExtendVisibility(ctx context.Context, id, receipt string, timeout time.Duration) (bool, error) {
+	n, err := reliableQueueExtendScripter.Run(ctx, q.r, []string{q.inflight, q.receipts}, timeout.Milliseconds(), id, receipt).Int()
+	return n == 1, err
+}
+
+/**
+ * 重新投递可见性超时的消息,投递次数达到上限的消息进入死信队列,每次最多处理100条
+ *
+ * return: int64 处理的消息数量
+ * return: error
+ */
+func (q *ReliableQueue[V]) Reap(ctx context.Context) (int64, error) {
+	return reliableQueueReapScripter.Run(ctx, q.r, q.requeueKeys(), q.opts.MaxDeliveries).Int64()
+}
+
+/**
+ * 等待消费的消息数量,不包括延迟中的消息
+ *
+ * return: int64
+ * return: error
+ */
+func (q *ReliableQueue[V]) Size(ctx context.Context) (int64, error) {
+	return q.r.LLen(ctx, q.name).Result()
+}
+
+/**
+ * 消费中还没有确认的消息数量
+ *
+ * return: int64
+ * return: error
+ */
+func (q *ReliableQueue[V]) InflightSize(ctx context.Context) (int64, error) {
+	return q.r.ZCard(ctx, q.inflight).Result()
+}
+
+/**
+ * 延迟重新投递的消息数量
+ *
+ * return: int64
+ * return: error
+ */
+func (q *ReliableQueue[V]) DelayedSize(ctx context.Context) (int64, error) {
+	return q.r.ZCard(ctx, q.delayed).Result()
+}
+
+/**
+ * 死信队列,保存投递次数达到上限的消息内容
+ *
+ * return: *Queue[V]
+ */
+func (q *ReliableQueue[V]) DeadLetterQueue() *Queue[V] {
+	return &Queue[V]{List: List[V]{object: object{r: q.r, name: q.dlq, codec: q.codec}}}
+}
+
+/**
+ * 删除队列及所有消息,不包括死信队列
+ *
+ * return: bool 删除前是否存在
+ * return: error
+ */
+func (q *ReliableQueue[V]) Delete(ctx context.Context) (bool, error) {
+	n, err := q.r.Del(ctx, q.keys()...).Result()
+	return n > 0, err
+}
+
+/**
+ * 停止后台任务,可以重复调用
+ */
+func (q *ReliableQueue[V]) Close() {
+	q.StopReaper()
+}
+
+func (q *ReliableQueue[V]) keys() []string {
+	return []string{q.name, q.items, q.inflight, q.attempts, q.delayed, q.receipts}
+}
+
+func (q *ReliableQueue[V]) requeueKeys() []string {
+	return append(q.keys(), q.dlq)
+}
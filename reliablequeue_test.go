@@ -0,0 +1,161 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func newTestReliableQueue(t *testing.T, opts ReliableQueueOptions) *ReliableQueue[string] {
+	t.Helper()
+	q := GetReliableQueueWithOptions[string](testRedis(t), "queue", nil, opts)
+	if _, err := q.Delete(context.Background()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := q.DeadLetterQueue().Delete(context.Background()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	return q
+}
+
+func pollReliable(t *testing.T, q *ReliableQueue[string]) ReliableMessage[string] {
+	t.Helper()
+	msg, ok, err := q.Poll(context.Background())
+	if err != nil || !ok {
+		t.Fatalf("Poll() = %v, %v, want a message", ok, err)
+	}
+	return msg
+}
+
+func TestReliableQueue_Ack(t *testing.T) {
+	ctx := context.Background()
+	q := newTestReliableQueue(t, ReliableQueueOptions{})
+	if _, err := q.Offer(ctx, "a"); err != nil {
+		t.Fatalf("Offer() error = %v", err)
+	}
+	msg := pollReliable(t, q)
+	if msg.Value != "a" || msg.Attempts != 1 || msg.Receipt == "" {
+		t.Fatalf("Poll() = %+v", msg)
+	}
+	tests := []struct {
+		name    string
+		id      string
+		receipt string
+		want    bool
+	}{
+		{"wrong receipt", msg.ID, "other", false},
+		{"unknown id", "other", msg.Receipt, false},
+		{"ack", msg.ID, msg.Receipt, true},
+		{"ack twice", msg.ID, msg.Receipt, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := q.Ack(ctx, tt.id, tt.receipt)
+			if err != nil {
+				t.Fatalf("Ack() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Ack() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if n, err := q.InflightSize(ctx); err != nil || n != 0 {
+		t.Errorf("InflightSize() = %v, %v, want 0", n, err)
+	}
+}
+
+func TestReliableQueue_Nack(t *testing.T) {
+	ctx := context.Background()
+	q := newTestReliableQueue(t, ReliableQueueOptions{})
+	if _, err := q.Offer(ctx, "a"); err != nil {
+		t.Fatalf("Offer() error = %v", err)
+	}
+	first := pollReliable(t, q)
+	if ok, err := q.Nack(ctx, first.ID, first.Receipt, 0); err != nil || !ok {
+		t.Fatalf("Nack() = %v, %v, want true", ok, err)
+	}
+	second := pollReliable(t, q)
+	if second.ID != first.ID || second.Attempts != 2 || second.Receipt == first.Receipt {
+		t.Fatalf("Poll() = %+v after Nack of %+v", second, first)
+	}
+	if ok, err := q.Ack(ctx, first.ID, first.Receipt); err != nil || ok {
+		t.Errorf("Ack() with the first receipt = %v, %v, want false", ok, err)
+	}
+	if ok, err := q.Nack(ctx, second.ID, second.Receipt, 100*time.Millisecond); err != nil || !ok {
+		t.Fatalf("Nack() = %v, %v, want true", ok, err)
+	}
+	if n, err := q.DelayedSize(ctx); err != nil || n != 1 {
+		t.Errorf("DelayedSize() = %v, %v, want 1", n, err)
+	}
+	if _, ok, err := q.Poll(ctx); err != nil || ok {
+		t.Errorf("Poll() before the delay = %v, %v, want false", ok, err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if third := pollReliable(t, q); third.ID != first.ID || third.Attempts != 3 {
+		t.Errorf("Poll() after the delay = %+v", third)
+	}
+}
+
+func TestReliableQueue_Reap(t *testing.T) {
+	ctx := context.Background()
+	q := newTestReliableQueue(t, ReliableQueueOptions{VisibilityTimeout: 100 * time.Millisecond})
+	if _, err := q.Offer(ctx, "a", "b"); err != nil {
+		t.Fatalf("Offer() error = %v", err)
+	}
+	a := pollReliable(t, q)
+	b := pollReliable(t, q)
+	if ok, err := q.ExtendVisibility(ctx, b.ID, b.Receipt, time.Minute); err != nil || !ok {
+		t.Fatalf("ExtendVisibility() = %v, %v, want true", ok, err)
+	}
+	if n, err := q.Reap(ctx); err != nil || n != 0 {
+		t.Errorf("Reap() before the timeout = %v, %v, want 0", n, err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if n, err := q.Reap(ctx); err != nil || n != 1 {
+		t.Fatalf("Reap() = %v, %v, want 1", n, err)
+	}
+	if ok, err := q.Ack(ctx, a.ID, a.Receipt); err != nil || ok {
+		t.Errorf("Ack() after the timeout = %v, %v, want false", ok, err)
+	}
+	if again := pollReliable(t, q); again.ID != a.ID || again.Attempts != 2 {
+		t.Errorf("Poll() after Reap = %+v", again)
+	}
+	if ok, err := q.Ack(ctx, b.ID, b.Receipt); err != nil || !ok {
+		t.Errorf("Ack() of the extended message = %v, %v, want true", ok, err)
+	}
+}
+
+func TestReliableQueue_deadLetter(t *testing.T) {
+	ctx := context.Background()
+	q := newTestReliableQueue(t, ReliableQueueOptions{VisibilityTimeout: 100 * time.Millisecond, MaxDeliveries: 2})
+	if _, err := q.Offer(ctx, "a", "b"); err != nil {
+		t.Fatalf("Offer() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		msg := pollReliable(t, q)
+		if ok, err := q.Nack(ctx, msg.ID, msg.Receipt, 0); err != nil || !ok {
+			t.Fatalf("Nack() = %v, %v, want true", ok, err)
+		}
+	}
+	pollReliable(t, q)
+	pollReliable(t, q)
+	time.Sleep(150 * time.Millisecond)
+	if n, err := q.Reap(ctx); err != nil || n != 2 {
+		t.Fatalf("Reap() = %v, %v, want 2", n, err)
+	}
+	got, err := q.DeadLetterQueue().ReadAll(ctx)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	sort.Strings(got)
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dead letters = %v, want %v", got, want)
+	}
+	for _, size := range []func(context.Context) (int64, error){q.Size, q.InflightSize, q.DelayedSize} {
+		if n, err := size(ctx); err != nil || n != 0 {
+			t.Errorf("size = %v, %v, want 0", n, err)
+		}
+	}
+}
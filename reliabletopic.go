@@ -0,0 +1,420 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	DefaultReliableTopicSubscriberTimeout = 10 * time.Minute
+	DefaultReliableTopicBatchSize         = 100
+	DefaultReliableTopicPollBlock         = time.Second
+	// 读取出错后的重试间隔
+	reliableTopicRetryDelay = time.Second
+
+	// 时间都使用服务端时间,避免多个实例之间的时钟偏差
+	// KEYS[1] stream KEYS[2] 订阅者 ARGV[1] 消息
+	// 没有存活的订阅者时不保存消息,返回收到消息的订阅者数量
+	ReliableTopicPublishScript = serverNowScript + `
+local n = redis.call('zcount', KEYS[2], '(' .. now, '+inf')
+if n > 0 then
+	redis.call('xadd', KEYS[1], '*', 'value', ARGV[1])
+end
+return n
+`
+	// KEYS[1] stream KEYS[2] 订阅者 KEYS[3] 读取位置 KEYS[4] 已删除的最大消息id ARGV[1] 订阅者id ARGV[2] 超时毫秒数
+	// 从当前最后一条消息之后开始读取,返回读取位置
+	ReliableTopicSubscribeScript = serverNowScript + `
+local last = redis.call('xrevrange', KEYS[1], '+', '-', 'count', 1)
+local offset = '0-0'
+if #last > 0 then
+	offset = last[1][1]
+end
+redis.call('zadd', KEYS[2], now + tonumber(ARGV[2]), ARGV[1])
+redis.call('hset', KEYS[3], ARGV[1], offset)
+return offset
+`
+	// KEYS同 ReliableTopicSubscribeScript ARGV[1] 订阅者id ARGV[2] 超时毫秒数 ARGV[3] 读取位置
+	// 更新心跳和读取位置,超时被删除的订阅者重新注册
+	// 超时期间读取位置之后的消息已经被删除时返回1,否则返回0
+	ReliableTopicCommitScript = serverNowScript + reliableTopicTrimScript + `
+local lost = 0
+if redis.call('zscore', KEYS[2], ARGV[1]) == false then
+	local trimmed = redis.call('get', KEYS[4])
+	if trimmed ~= false and less(ARGV[3], trimmed) then
+		lost = 1
+	end
+end
+redis.call('zadd', KEYS[2], now + tonumber(ARGV[2]), ARGV[1])
+redis.call('hset', KEYS[3], ARGV[1], ARGV[3])
+trim(now)
+return lost
+`
+	// KEYS同 ReliableTopicSubscribeScript ARGV[1] 订阅者id
+	ReliableTopicUnsubscribeScript = serverNowScript + reliableTopicTrimScript + `
+redis.call('zrem', KEYS[2], ARGV[1])
+redis.call('hdel', KEYS[3], ARGV[1])
+trim(now)
+`
+	ReliableTopicCountSubscribersScript = serverNowScript + "return redis.call('zcount', KEYS[1], '(' .. now, '+inf')"
+
+	// 删除超时的订阅者,然后删除所有存活订阅者都已经读取过的消息,并记录已删除的最大消息id
+	// 重新注册的订阅者读取位置小于这个id时说明有未读取的消息被删除
+	reliableTopicTrimScript = `
+local function parse(id)
+	local ms, seq = string.match(id, '(%d+)-(%d+)')
+	return tonumber(ms), tonumber(seq)
+end
+local function less(a, b)
+	local ams, aseq = parse(a)
+	local bms, bseq = parse(b)
+	return ams < bms or (ams == bms and aseq < bseq)
+end
+local function trimmed(id)
+	local prev = redis.call('get', KEYS[4])
+	if prev == false or less(prev, id) then
+		redis.call('set', KEYS[4], id)
+	end
+end
+local function trim(now)
+	for _, id in ipairs(redis.call('zrangebyscore', KEYS[2], '-inf', now)) do
+		redis.call('zrem', KEYS[2], id)
+		redis.call('hdel', KEYS[3], id)
+	end
+	local offsets = redis.call('hvals', KEYS[3])
+	if #offsets == 0 then
+		local last = redis.call('xrevrange', KEYS[1], '+', '-', 'count', 1)
+		if #last > 0 then
+			trimmed(last[1][1])
+		end
+		redis.call('xtrim', KEYS[1], 'maxlen', 0)
+		return
+	end
+	local min = offsets[1]
+	for i = 2, #offsets do
+		if less(offsets[i], min) then
+			min = offsets[i]
+		end
+	end
+	local last = redis.call('xrevrange', KEYS[1], min, '-', 'count', 1)
+	if #last > 0 then
+		local ms, seq = parse(min)
+		redis.call('xtrim', KEYS[1], 'minid', string.format('%d-%d', ms, seq + 1))
+		trimmed(last[1][1])
+	end
+end
+`
+)
+
+var (
+	reliableTopicPublishScripter     = gredis.NewScript(ReliableTopicPublishScript)
+	reliableTopicSubscribeScripter   = gredis.NewScript(ReliableTopicSubscribeScript)
+	reliableTopicCommitScripter      = gredis.NewScript(ReliableTopicCommitScript)
+	reliableTopicUnsubscribeScripter = gredis.NewScript(ReliableTopicUnsubscribeScript)
+	reliableTopicCountScripter       = gredis.NewScript(ReliableTopicCountSubscribersScript)
+)
+
+// ReliableTopicOptions ReliableTopic的配置
+type ReliableTopicOptions struct {
+	// 订阅者超过这个时间没有心跳时视为已经下线,不再为它保留消息,0时使用 DefaultReliableTopicSubscriberTimeout
+	SubscriberTimeout time.Duration
+	BatchSize         int64         // 每次读取的最大消息数量,0时使用 DefaultReliableTopicBatchSize
+	PollBlock         time.Duration // 没有新消息时每次阻塞读取的时间,0时使用 DefaultReliableTopicPollBlock
+	// 订阅者超时后重新注册时,如果超时期间有未读取的消息已经被删除,在读取消息的goroutine中调用
+	OnMessagesLost func()
+}
+
+// ReliableTopic 基于stream的可靠发布订阅,每个实例作为一个订阅者记录自己的读取位置
+// 短暂断开后从上次的位置继续读取,所有存活的订阅者都读取过的消息会被删除
+// 需要redis 6.2
+type ReliableTopic[V any] struct {
+	object
+	opts         ReliableTopicOptions
+	subscribers  string
+	offsets      string
+	trimmed      string
+	mu           sync.Mutex
+	nextId       int64
+	listeners    map[int64]func(msg V)
+	subscriberId string
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+/**
+ * 获取ReliableTopic
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *ReliableTopic[V]
+ */
+func GetReliableTopic[V any](r *Redis, name string) *ReliableTopic[V] {
+	return GetReliableTopicWithOptions[V](r, name, nil, ReliableTopicOptions{})
+}
+
+/**
+ * 获取使用指定codec的ReliableTopic
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *ReliableTopic[V]
+ */
+func GetReliableTopicWithCodec[V any](r *Redis, name string, c codec.Codec) *ReliableTopic[V] {
+	return GetReliableTopicWithOptions[V](r, name, c, ReliableTopicOptions{})
+}
+
+/**
+ * 获取指定配置的ReliableTopic
+ *
+ * param: *Redis               r
+ * param: string               name
+ * param: codec.Codec          c    为nil时使用客户端默认的codec
+ * param: ReliableTopicOptions opts
+ * return: *ReliableTopic[V]
+ */
+func GetReliableTopicWithOptions[V any](r *Redis, name string, c codec.Codec, opts ReliableTopicOptions) *ReliableTopic[V] {
+	if opts.SubscriberTimeout <= 0 {
+		opts.SubscriberTimeout = DefaultReliableTopicSubscriberTimeout
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultReliableTopicBatchSize
+	}
+	if opts.PollBlock <= 0 {
+		opts.PollBlock = DefaultReliableTopicPollBlock
+	}
+	o := r.newObject(name, c)
+	return &ReliableTopic[V]{
+		object:      o,
+		opts:        opts,
+		subscribers: suffixName(o.name, "subscribers"),
+		offsets:     suffixName(o.name, "offsets"),
+		trimmed:     suffixName(o.name, "trimmed"),
+		listeners:   make(map[int64]func(msg V)),
+	}
+}
+
+/**
+ * 发布消息,没有存活的订阅者时消息不会保存
+ *
+ * param: V msg
+ * return: int64 存活的订阅者数量
+ * return: error
+ */
+func (t *ReliableTopic[V]) Publish(ctx context.Context, msg V) (int64, error) {
+	data, err := t.encode(msg)
+	if err != nil {
+		return 0, err
+	}
+	return reliableTopicPublishScripter.Run(ctx, t.r, []string{t.name, t.subscribers}, data).Int64()
+}
+
+/**
+ * 添加监听器,第一个监听器添加时注册订阅者并开始读取,只接收注册之后发布的消息
+ * 监听器在读取消息的goroutine中依次执行
+ *
+ * param: func(msg V) f 无法解码的消息会被忽略
+ * return: int64 监听器id,用于RemoveListener
+ * return: error
+ */
+func (t *ReliableTopic[V]) AddListener(ctx context.Context, f func(msg V)) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.listeners) == 0 {
+		if err := t.subscribe(ctx); err != nil {
+			return 0, err
+		}
+	}
+	t.nextId++
+	t.listeners[t.nextId] = f
+	return t.nextId, nil
+}
+
+/**
+ * 删除监听器,最后一个监听器删除后注销订阅者
+ *
+ * param: ...int64 ids
+ * return: error
+ */
+func (t *ReliableTopic[V]) RemoveListener(ctx context.Context, ids ...int64) error {
+	t.mu.Lock()
+	if len(t.listeners) == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	for _, id := range ids {
+		delete(t.listeners, id)
+	}
+	if len(t.listeners) > 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	cancel, done, id := t.cancel, t.done, t.subscriberId
+	t.mu.Unlock()
+	// 读取goroutine执行监听器时需要获取锁,在锁外等待它退出
+	cancel()
+	<-done
+	return reliableTopicUnsubscribeScripter.Run(ctx, t.r, t.keys(), id).Err()
+}
+
+/**
+ * 删除全部监听器并注销订阅者
+ *
+ * return: error
+ */
+func (t *ReliableTopic[V]) RemoveAllListeners(ctx context.Context) error {
+	t.mu.Lock()
+	ids := make([]int64, 0, len(t.listeners))
+	for id := range t.listeners {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+	return t.RemoveListener(ctx, ids...)
+}
+
+/**
+ * 当前实例的监听器数量
+ *
+ * return: int
+ */
+func (t *ReliableTopic[V]) CountListeners() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.listeners)
+}
+
+/**
+ * 存活的订阅者数量
+ *
+ * return: int64
+ * return: error
+ */
+func (t *ReliableTopic[V]) CountSubscribers(ctx context.Context) (int64, error) {
+	return reliableTopicCountScripter.Run(ctx, t.r, []string{t.subscribers}).Int64()
+}
+
+/**
+ * 还没有被所有订阅者读取的消息数量
+ *
+ * return: int64
+ * return: error
+ */
+func (t *ReliableTopic[V]) Size(ctx context.Context) (int64, error) {
+	return t.r.XLen(ctx, t.name).Result()
+}
+
+/**
+ * 删除全部消息和订阅者信息,其他实例的订阅者会在下次心跳时重新注册
+ *
+ * return: bool 删除前是否存在
+ * return: error
+ */
+func (t *ReliableTopic[V]) Delete(ctx context.Context) (bool, error) {
+	n, err := t.r.Del(ctx, t.keys()...).Result()
+	return n > 0, err
+}
+
+/**
+ * 删除全部监听器并注销订阅者,可以重复调用
+ *
+ * return: error
+ */
+func (t *ReliableTopic[V]) Close() error {
+	return t.RemoveAllListeners(context.Background())
+}
+
+func (t *ReliableTopic[V]) subscribe(ctx context.Context) error {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	offset, err := reliableTopicSubscribeScripter.Run(ctx, t.r, t.keys(), id, t.opts.SubscriberTimeout.Milliseconds()).Text()
+	if err != nil {
+		return err
+	}
+	var runCtx context.Context
+	runCtx, t.cancel = context.WithCancel(context.Background())
+	t.subscriberId = id
+	t.done = make(chan struct{})
+	go t.poll(runCtx, id, offset, t.done)
+	return nil
+}
+
+/**
+ * 循环读取新消息并分发给监听器,每批消息处理完后提交读取位置,没有新消息时定期发送心跳
+ *
+ * param: string        id     订阅者id
+ * param: string        offset 最后读取的消息id
+ * param: chan struct{} done
+ */
+func (t *ReliableTopic[V]) poll(ctx context.Context, id, offset string, done chan struct{}) {
+	defer close(done)
+	client := t.r.blockingClient()
+	heartbeat := t.opts.SubscriberTimeout / 3
+	lastCommit := time.Now()
+	for ctx.Err() == nil {
+		res, err := client.XRead(ctx, &gredis.XReadArgs{
+			Streams: []string{t.name, offset},
+			Count:   t.opts.BatchSize,
+			Block:   t.opts.PollBlock,
+		}).Result()
+		if err != nil && err != gredis.Nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(reliableTopicRetryDelay):
+			}
+			continue
+		}
+		read := false
+		for _, stream := range res {
+			for _, msg := range stream.Messages {
+				t.dispatch(msg)
+				offset, read = msg.ID, true
+			}
+		}
+		if !read && time.Since(lastCommit) < heartbeat {
+			continue
+		}
+		lost, err := reliableTopicCommitScripter.Run(ctx, t.r, t.keys(), id, t.opts.SubscriberTimeout.Milliseconds(), offset).Int()
+		if err != nil {
+			continue
+		}
+		lastCommit = time.Now()
+		if lost == 1 && t.opts.OnMessagesLost != nil {
+			t.opts.OnMessagesLost()
+		}
+	}
+}
+
+func (t *ReliableTopic[V]) dispatch(msg gredis.XMessage) {
+	data, ok := msg.Values[streamValueField].(string)
+	if !ok {
+		return
+	}
+	v, err := decodeAs[V](t.codec, data)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	fs := make([]func(msg V), 0, len(t.listeners))
+	for _, f := range t.listeners {
+		fs = append(fs, f)
+	}
+	t.mu.Unlock()
+	for _, f := range fs {
+		f(v)
+	}
+}
+
+func (t *ReliableTopic[V]) keys() []string {
+	return []string{t.name, t.subscribers, t.offsets, t.trimmed}
+}
@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"reflect"
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+func TestReliableTopic_dispatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       gredis.XMessage
+		listeners int
+		want      []int
+	}{
+		{"fan out", gredis.XMessage{ID: "1-0", Values: map[string]interface{}{streamValueField: "7"}}, 2, []int{7, 7}},
+		{"no listener", gredis.XMessage{ID: "1-0", Values: map[string]interface{}{streamValueField: "7"}}, 0, nil},
+		{"deleted", gredis.XMessage{ID: "1-0"}, 1, nil},
+		{"undecodable", gredis.XMessage{ID: "1-0", Values: map[string]interface{}{streamValueField: "x"}}, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			topic := &ReliableTopic[int]{object: object{codec: codec.JSON}, listeners: make(map[int64]func(msg int))}
+			for i := 0; i < tt.listeners; i++ {
+				topic.listeners[int64(i)] = func(msg int) { got = append(got, msg) }
+			}
+			topic.dispatch(tt.msg)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dispatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package redis
+
+import (
+	"context"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	// KEYS[1] 队列 KEYS[2] 容量 ARGV[1] 容量 ARGV[2] 为1时覆盖已有的容量
+	// 设置后只保留最新的元素
+	RingBufferSetCapacityScript = ringBufferTrimScript + `
+if ARGV[2] ~= '1' and redis.call('exists', KEYS[2]) == 1 then
+	return 0
+end
+redis.call('set', KEYS[2], ARGV[1])
+trim(tonumber(ARGV[1]))
+return 1
+`
+	// KEYS[1] 队列 KEYS[2] 容量 ARGV 元素
+	// 返回添加后的元素数量,容量没有设置时返回-1
+	RingBufferAddScript = ringBufferTrimScript + `
+local capacity = redis.call('get', KEYS[2])
+if capacity == false then
+	return -1
+end
+redis.call('rpush', KEYS[1], unpack(ARGV))
+trim(tonumber(capacity))
+return redis.call('llen', KEYS[1])
+`
+	// LTRIM的起始下标为-0时不会删除元素,容量为0时直接删除队列
+	ringBufferTrimScript = `
+local function trim(capacity)
+	if capacity > 0 then
+		redis.call('ltrim', KEYS[1], -capacity, -1)
+	else
+		redis.call('del', KEYS[1])
+	end
+end
+`
+	// 返回剩余容量,容量没有设置时返回-1
+	RingBufferRemainingCapacityScript = `
+local capacity = redis.call('get', KEYS[2])
+if capacity == false then
+	return -1
+end
+return tonumber(capacity) - redis.call('llen', KEYS[1])
+`
+)
+
+var (
+	ringBufferSetCapacityScripter       = gredis.NewScript(RingBufferSetCapacityScript)
+	ringBufferAddScripter               = gredis.NewScript(RingBufferAddScript)
+	ringBufferRemainingCapacityScripter = gredis.NewScript(RingBufferRemainingCapacityScript)
+)
+
+// RingBuffer 固定容量的队列,添加元素超出容量时删除最早的元素,容量保存在关联的key中
+type RingBuffer[V any] struct {
+	object
+	list     *List[V]
+	capacity string
+}
+
+/**
+ * 获取RingBuffer,使用前需要通过TrySetCapacity或SetCapacity设置容量
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *RingBuffer[V]
+ */
+func GetRingBuffer[V any](r *Redis, name string) *RingBuffer[V] {
+	return GetRingBufferWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的RingBuffer,使用前需要通过TrySetCapacity或SetCapacity设置容量
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *RingBuffer[V]
+ */
+func GetRingBufferWithCodec[V any](r *Redis, name string, c codec.Codec) *RingBuffer[V] {
+	l := GetListWithCodec[V](r, name, c)
+	return &RingBuffer[V]{object: l.object, list: l, capacity: suffixName(l.name, "capacity")}
+}
+
+/**
+ * 容量没有设置过时设置容量,已有元素超出容量时删除最早的元素
+ *
+ * param: int64 capacity 不能为负数
+ * return: bool 是否设置成功
+ * return: error
+ */
+func (b *RingBuffer[V]) TrySetCapacity(ctx context.Context, capacity int64) (bool, error) {
+	if capacity < 0 {
+		return false, ErrInvalidCapacity
+	}
+	n, err := ringBufferSetCapacityScripter.Run(ctx, b.r, b.keys(), capacity, 0).Int()
+	return n == 1, err
+}
+
+/**
+ * 设置容量,已有元素超出容量时删除最早的元素
+ *
+ * param: int64 capacity 不能为负数
+ * return: error
+ */
+func (b *RingBuffer[V]) SetCapacity(ctx context.Context, capacity int64) error {
+	if capacity < 0 {
+		return ErrInvalidCapacity
+	}
+	return ringBufferSetCapacityScripter.Run(ctx, b.r, b.keys(), capacity, 1).Err()
+}
+
+/**
+ * 容量
+ *
+ * return: int64
+ * return: error 容量没有设置时返回 ErrRingBufferCapacityNotSet
+ */
+func (b *RingBuffer[V]) Capacity(ctx context.Context) (int64, error) {
+	n, err := b.r.Get(ctx, b.capacity).Int64()
+	if err == gredis.Nil {
+		return 0, ErrRingBufferCapacityNotSet
+	}
+	return n, err
+}
+
+/**
+ * 剩余容量
+ *
+ * return: int64
+ * return: error 容量没有设置时返回 ErrRingBufferCapacityNotSet
+ */
+func (b *RingBuffer[V]) RemainingCapacity(ctx context.Context) (int64, error) {
+	n, err := ringBufferRemainingCapacityScripter.Run(ctx, b.r, b.keys()).Int64()
+	if err == nil && n < 0 {
+		return 0, ErrRingBufferCapacityNotSet
+	}
+	return n, err
+}
+
+/**
+ * 在末尾添加元素,超出容量时删除最早的元素
+ *
+ * param: ...V values
+ * return: int64 添加后的元素数量
+ * return: error 容量没有设置时返回 ErrRingBufferCapacityNotSet
+ */
+func (b *RingBuffer[V]) Add(ctx context.Context, values ...V) (int64, error) {
+	if len(values) == 0 {
+		return b.Size(ctx)
+	}
+	items, err := encodeValues(&b.object, values)
+	if err != nil {
+		return 0, err
+	}
+	n, err := ringBufferAddScripter.Run(ctx, b.r, b.keys(), items...).Int64()
+	if err == nil && n < 0 {
+		return 0, ErrRingBufferCapacityNotSet
+	}
+	return n, err
+}
+
+/**
+ * 取出最早的元素
+ *
+ * return: V
+ * return: bool 为空时返回false
+ * return: error
+ */
+func (b *RingBuffer[V]) Poll(ctx context.Context) (V, bool, error) {
+	return b.list.decodeResult(b.r.LPop(ctx, b.name).Result())
+}
+
+/**
+ * 读取最早的元素,不取出
+ *
+ * return: V
+ * return: bool 为空时返回false
+ * return: error
+ */
+func (b *RingBuffer[V]) Peek(ctx context.Context) (V, bool, error) {
+	return b.list.Get(ctx, 0)
+}
+
+/**
+ * 读取下标对应的元素,0为最早的元素,下标为负数时从最新的元素开始
+ *
+ * param: int64 index
+ * return: V
+ * return: bool 下标是否存在
+ * return: error
+ */
+func (b *RingBuffer[V]) Get(ctx context.Context, index int64) (V, bool, error) {
+	return b.list.Get(ctx, index)
+}
+
+/**
+ * 读取下标区间内的元素,包含start和end,下标为负数时从最新的元素开始
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: []V
+ * return: error
+ */
+func (b *RingBuffer[V]) Range(ctx context.Context, start, end int64) ([]V, error) {
+	return b.list.Range(ctx, start, end)
+}
+
+/**
+ * 按添加顺序读取全部元素
+ *
+ * return: []V
+ * return: error
+ */
+func (b *RingBuffer[V]) ReadAll(ctx context.Context) ([]V, error) {
+	return b.list.ReadAll(ctx)
+}
+
+/**
+ * 元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (b *RingBuffer[V]) Size(ctx context.Context) (int64, error) {
+	return b.list.Size(ctx)
+}
+
+/**
+ * 删除全部元素,保留容量
+ *
+ * return: error
+ */
+func (b *RingBuffer[V]) Clear(ctx context.Context) error {
+	return b.r.Del(ctx, b.name).Err()
+}
+
+/**
+ * 删除元素及容量
+ *
+ * return: bool 删除前是否存在
+ * return: error
+ */
+func (b *RingBuffer[V]) Delete(ctx context.Context) (bool, error) {
+	n, err := b.r.Del(ctx, b.keys()...).Result()
+	return n > 0, err
+}
+
+func (b *RingBuffer[V]) keys() []string {
+	return []string{b.name, b.capacity}
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRingBuffer_invalidCapacity(t *testing.T) {
+	ctx := context.Background()
+	b := GetRingBuffer[string](&Redis{}, "events")
+	if _, err := b.TrySetCapacity(ctx, -1); err != ErrInvalidCapacity {
+		t.Errorf("TrySetCapacity() error = %v, want %v", err, ErrInvalidCapacity)
+	}
+	if err := b.SetCapacity(ctx, -1); err != ErrInvalidCapacity {
+		t.Errorf("SetCapacity() error = %v, want %v", err, ErrInvalidCapacity)
+	}
+}
+
+func TestRingBuffer_Add(t *testing.T) {
+	ctx := context.Background()
+	b := GetRingBuffer[string](testRedis(t), "events")
+	if _, err := b.Delete(ctx); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := b.Add(ctx, "a"); err != ErrRingBufferCapacityNotSet {
+		t.Fatalf("Add() error = %v, want %v", err, ErrRingBufferCapacityNotSet)
+	}
+	if ok, err := b.TrySetCapacity(ctx, 3); err != nil || !ok {
+		t.Fatalf("TrySetCapacity() = %v, %v, want true", ok, err)
+	}
+	if ok, err := b.TrySetCapacity(ctx, 5); err != nil || ok {
+		t.Fatalf("TrySetCapacity() again = %v, %v, want false", ok, err)
+	}
+	if n, err := b.Capacity(ctx); err != nil || n != 3 {
+		t.Fatalf("Capacity() = %v, %v, want 3", n, err)
+	}
+	tests := []struct {
+		name      string
+		values    []string
+		size      int64
+		remaining int64
+		want      []string
+	}{
+		{"add", []string{"a"}, 1, 2, []string{"a"}},
+		{"fill", []string{"b", "c"}, 3, 0, []string{"a", "b", "c"}},
+		{"evict oldest", []string{"d"}, 3, 0, []string{"b", "c", "d"}},
+		{"evict several", []string{"e", "f", "g", "h"}, 3, 0, []string{"f", "g", "h"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := b.Add(ctx, tt.values...)
+			if err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			if n != tt.size {
+				t.Errorf("Add() = %v, want %v", n, tt.size)
+			}
+			if n, err := b.RemainingCapacity(ctx); err != nil || n != tt.remaining {
+				t.Errorf("RemainingCapacity() = %v, %v, want %v", n, err, tt.remaining)
+			}
+			if got, err := b.ReadAll(ctx); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadAll() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRingBuffer_SetCapacity(t *testing.T) {
+	ctx := context.Background()
+	b := GetRingBuffer[string](testRedis(t), "events")
+	if _, err := b.Delete(ctx); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		capacity  int64
+		remaining int64
+		want      []string
+	}{
+		{"grow", 5, 1, []string{"a", "b", "c", "d"}},
+		{"shrink", 2, 0, []string{"c", "d"}},
+		{"zero", 0, 0, nil},
+	}
+	if err := b.SetCapacity(ctx, 4); err != nil {
+		t.Fatalf("SetCapacity() error = %v", err)
+	}
+	if _, err := b.Add(ctx, "a", "b", "c", "d"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := b.SetCapacity(ctx, tt.capacity); err != nil {
+				t.Fatalf("SetCapacity() error = %v", err)
+			}
+			if n, err := b.RemainingCapacity(ctx); err != nil || n != tt.remaining {
+				t.Errorf("RemainingCapacity() = %v, %v, want %v", n, err, tt.remaining)
+			}
+			got, err := b.ReadAll(ctx)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("ReadAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package redis
+
+import (
+	"context"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+// Set 基于set的分布式集合,元素使用codec编码
+type Set[V any] struct {
+	object
+}
+
+// SetIterator 基于SSCAN遍历集合,遍历期间有修改时同一个元素可能返回多次
+type SetIterator[V any] struct {
+	o     *object
+	scan  scanIterator
+	value V
+	err   error
+}
+
+/**
+ * 获取Set
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *Set[V]
+ */
+func GetSet[V any](r *Redis, name string) *Set[V] {
+	return GetSetWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的Set
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *Set[V]
+ */
+func GetSetWithCodec[V any](r *Redis, name string, c codec.Codec) *Set[V] {
+	return &Set[V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 添加元素
+ *
+ * param: ...V values
+ * return: int64 新增的数量
+ * return: error
+ */
+func (s *Set[V]) Add(ctx context.Context, values ...V) (int64, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	members, err := encodeValues(&s.object, values)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.SAdd(ctx, s.name, members...).Result()
+}
+
+/**
+ * 删除元素
+ *
+ * param: ...V values
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *Set[V]) Remove(ctx context.Context, values ...V) (int64, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	members, err := encodeValues(&s.object, values)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.SRem(ctx, s.name, members...).Result()
+}
+
+/**
+ * 是否包含元素
+ *
+ * param: V value
+ * return: bool
+ * return: error
+ */
+func (s *Set[V]) Contains(ctx context.Context, value V) (bool, error) {
+	member, err := s.encode(value)
+	if err != nil {
+		return false, err
+	}
+	return s.r.SIsMember(ctx, s.name, member).Result()
+}
+
+/**
+ * 元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (s *Set[V]) Size(ctx context.Context) (int64, error) {
+	return s.r.SCard(ctx, s.name).Result()
+}
+
+/**
+ * 读取全部元素
+ *
+ * return: []V
+ * return: error
+ */
+func (s *Set[V]) ReadAll(ctx context.Context) ([]V, error) {
+	return decodeValues[V](s.codec)(s.r.SMembers(ctx, s.name).Result())
+}
+
+/**
+ * 随机删除并返回一个元素
+ *
+ * return: V
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *Set[V]) RemoveRandom(ctx context.Context) (V, bool, error) {
+	var v V
+	data, err := s.r.SPop(ctx, s.name).Result()
+	if err == gredis.Nil {
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	v, err = decodeAs[V](s.codec, data)
+	return v, err == nil, err
+}
+
+/**
+ * 随机返回count个不重复的元素
+ *
+ * param: int64 count
+ * return: []V
+ * return: error
+ */
+func (s *Set[V]) Random(ctx context.Context, count int64) ([]V, error) {
+	return decodeValues[V](s.codec)(s.r.SRandMemberN(ctx, s.name, count).Result())
+}
+
+/**
+ * 把元素移动到另一个集合,集群模式下两个集合需要位于同一个slot
+ *
+ * param: string dest
+ * param: V      value
+ * return: bool 元素是否存在
+ * return: error
+ */
+func (s *Set[V]) Move(ctx context.Context, dest string, value V) (bool, error) {
+	dest = s.r.namespaced(dest)
+	if err := s.r.checkSameSlot(s.name, dest); err != nil {
+		return false, err
+	}
+	member, err := s.encode(value)
+	if err != nil {
+		return false, err
+	}
+	return s.r.SMove(ctx, s.name, dest, member).Result()
+}
+
+/**
+ * 把当前集合和names的并集保存到dest,dest已经存在时被覆盖,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *Set[V]) Union(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.SUnionStore(ctx, dest, keys...).Result()
+}
+
+/**
+ * 把当前集合和names的交集保存到dest,dest已经存在时被覆盖,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *Set[V]) Intersect(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.SInterStore(ctx, dest, keys...).Result()
+}
+
+/**
+ * 把当前集合与names的差集保存到dest,dest已经存在时被覆盖,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *Set[V]) Diff(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.SDiffStore(ctx, dest, keys...).Result()
+}
+
+/**
+ * 读取当前集合和names的并集
+ *
+ * param: ...string names
+ * return: []V
+ * return: error
+ */
+func (s *Set[V]) ReadUnion(ctx context.Context, names ...string) ([]V, error) {
+	keys, err := s.withNames(names)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues[V](s.codec)(s.r.SUnion(ctx, keys...).Result())
+}
+
+/**
+ * 读取当前集合和names的交集
+ *
+ * param: ...string names
+ * return: []V
+ * return: error
+ */
+func (s *Set[V]) ReadIntersect(ctx context.Context, names ...string) ([]V, error) {
+	keys, err := s.withNames(names)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues[V](s.codec)(s.r.SInter(ctx, keys...).Result())
+}
+
+/**
+ * 读取当前集合与names的差集
+ *
+ * param: ...string names
+ * return: []V
+ * return: error
+ */
+func (s *Set[V]) ReadDiff(ctx context.Context, names ...string) ([]V, error) {
+	keys, err := s.withNames(names)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues[V](s.codec)(s.r.SDiff(ctx, keys...).Result())
+}
+
+/**
+ * 获取迭代器
+ *
+ * param: int64 count 每次SSCAN的数量
+ * return: *SetIterator[V]
+ */
+func (s *Set[V]) Iterator(count int64) *SetIterator[V] {
+	it := &SetIterator[V]{o: &s.object}
+	it.scan.scan = func(ctx context.Context, cursor uint64) ([]string, uint64, error) {
+		return s.r.SScan(ctx, s.name, cursor, "", count).Result()
+	}
+	return it
+}
+
+/**
+ * 当前对象的key加上names对应的key,并检查是否位于同一个slot
+ *
+ * param: []string names
+ * return: []string
+ * return: error
+ */
+func (o *object) withNames(names []string) ([]string, error) {
+	keys := make([]string, 0, len(names)+1)
+	keys = append(keys, o.name)
+	for _, name := range names {
+		keys = append(keys, o.r.namespaced(name))
+	}
+	if err := o.r.checkSameSlot(keys...); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+/**
+ * 集合运算的目标key和参与计算的key,并检查是否位于同一个slot
+ *
+ * param: string   dest
+ * param: []string names
+ * return: string   目标key
+ * return: []string 当前对象的key加上names对应的key
+ * return: error
+ */
+func (o *object) withDest(dest string, names []string) (string, []string, error) {
+	dest = o.r.namespaced(dest)
+	keys, err := o.withNames(names)
+	if err != nil {
+		return "", nil, err
+	}
+	if err = o.r.checkSameSlot(dest, o.name); err != nil {
+		return "", nil, err
+	}
+	return dest, keys, nil
+}
+
+func encodeValues[V any](o *object, values []V) ([]interface{}, error) {
+	members := make([]interface{}, len(values))
+	for i, v := range values {
+		member, err := o.encode(v)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = member
+	}
+	return members, nil
+}
+
+/**
+ * 返回解码命令结果的函数,用于直接包装 Result() 的返回值
+ *
+ * param: codec.Codec c
+ * return: func([]string, error) ([]V, error)
+ */
+func decodeValues[V any](c codec.Codec) func([]string, error) ([]V, error) {
+	return func(items []string, err error) ([]V, error) {
+		if err != nil {
+			return nil, err
+		}
+		values := make([]V, len(items))
+		for i, item := range items {
+			if values[i], err = decodeAs[V](c, item); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+}
+
+/**
+ * 移动到下一个元素
+ *
+ * return: bool 是否还有元素
+ */
+func (it *SetIterator[V]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	items := it.scan.next(ctx, 1)
+	if items == nil {
+		it.err = it.scan.err
+		return false
+	}
+	it.value, it.err = decodeAs[V](it.o.codec, items[0])
+	return it.err == nil
+}
+
+/**
+ * 当前元素
+ *
+ * return: V
+ */
+func (it *SetIterator[V]) Value() V {
+	return it.value
+}
+
+/**
+ * 遍历过程中的错误
+ *
+ * return: error
+ */
+func (it *SetIterator[V]) Err() error {
+	return it.err
+}
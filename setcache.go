@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	// count小于等于0时每次扫描的数量,与SCAN命令默认的COUNT一致
+	setCacheScanCount = 10
+
+	// 过期时间都使用服务端时间,避免多个实例之间的时钟偏差
+	// ARGV[1] 存活毫秒数,为0时不过期 ARGV[2] 元素
+	// 不过期的元素使用一个足够大的过期时间
+	SetCacheAddScript = serverNowScript + `
+local expireAt = 92233720368547758
+if tonumber(ARGV[1]) > 0 then
+	expireAt = now + tonumber(ARGV[1])
+end
+local score = redis.call('zscore', KEYS[1], ARGV[2])
+redis.call('zadd', KEYS[1], expireAt, ARGV[2])
+if score == false or tonumber(score) <= now then
+	return 1
+end
+return 0
+`
+	// ARGV[1] 元素
+	SetCacheContainsScript = serverNowScript + `
+local score = redis.call('zscore', KEYS[1], ARGV[1])
+if score ~= false and tonumber(score) > now then
+	return 1
+end
+return 0
+`
+	// ARGV[1] 游标 ARGV[2] 每次扫描的数量
+	// 返回值第一项为下一次的游标,其余为未过期的元素
+	SetCacheScanScript = serverNowScript + `
+local res = redis.call('zscan', KEYS[1], ARGV[1], 'count', ARGV[2])
+local members = {res[1]}
+for i = 1, #res[2], 2 do
+	if tonumber(res[2][i + 1]) > now then
+		table.insert(members, res[2][i])
+	end
+end
+return members
+`
+	SetCacheSizeScript    = serverNowScript + "return redis.call('zcount', KEYS[1], '(' .. now, '+inf')"
+	SetCacheReadAllScript = serverNowScript + "return redis.call('zrangebyscore', KEYS[1], '(' .. now, '+inf')"
+	SetCacheEvictScript   = serverNowScript + "return redis.call('zremrangebyscore', KEYS[1], '-inf', now)"
+)
+
+var (
+	setCacheAddScripter      = gredis.NewScript(SetCacheAddScript)
+	setCacheContainsScripter = gredis.NewScript(SetCacheContainsScript)
+	setCacheScanScripter     = gredis.NewScript(SetCacheScanScript)
+	setCacheSizeScripter     = gredis.NewScript(SetCacheSizeScript)
+	setCacheReadAllScripter  = gredis.NewScript(SetCacheReadAllScript)
+	setCacheEvictScripter    = gredis.NewScript(SetCacheEvictScript)
+)
+
+// SetCache 每个元素可以单独设置过期时间的集合,基于以过期时间为分数的有序集合
+// 读取时忽略已经过期的元素,后台清理任务负责物理删除
+type SetCache[V any] struct {
+	object
+	eviction *evictionTask
+}
+
+/**
+ * 获取SetCache
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *SetCache[V]
+ */
+func GetSetCache[V any](r *Redis, name string) *SetCache[V] {
+	return GetSetCacheWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的SetCache
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *SetCache[V]
+ */
+func GetSetCacheWithCodec[V any](r *Redis, name string, c codec.Codec) *SetCache[V] {
+	return &SetCache[V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 启动后台清理任务,多个实例同时启动时通过分布式锁保证只有一个实例在清理
+ *
+ * param: time.Duration interval
+ */
+func (s *SetCache[V]) StartEviction(interval time.Duration) {
+	if s.eviction != nil {
+		return
+	}
+	s.eviction = s.r.startEviction(suffixName(s.name, "eviction"), interval, s.evict)
+}
+
+/**
+ * 停止后台清理任务
+ */
+func (s *SetCache[V]) StopEviction() {
+	s.eviction.close()
+}
+
+/**
+ * 添加元素,已经存在时更新过期时间
+ *
+ * param: V             value
+ * param: time.Duration ttl 为0时不过期
+ * return: bool 是否是新增的元素,已经过期的元素视为新增
+ * return: error
+ */
+func (s *SetCache[V]) Add(ctx context.Context, value V, ttl time.Duration) (bool, error) {
+	member, err := s.encode(value)
+	if err != nil {
+		return false, err
+	}
+	n, err := setCacheAddScripter.Run(ctx, s.r, []string{s.name}, ttl.Milliseconds(), member).Int()
+	return n == 1, err
+}
+
+/**
+ * 是否包含未过期的元素
+ *
+ * param: V value
+ * return: bool
+ * return: error
+ */
+func (s *SetCache[V]) Contains(ctx context.Context, value V) (bool, error) {
+	member, err := s.encode(value)
+	if err != nil {
+		return false, err
+	}
+	n, err := setCacheContainsScripter.Run(ctx, s.r, []string{s.name}, member).Int()
+	return n == 1, err
+}
+
+/**
+ * 删除元素
+ *
+ * param: ...V values
+ * return: int64 删除的数量,包括已经过期但还没有清理的元素
+ * return: error
+ */
+func (s *SetCache[V]) Remove(ctx context.Context, values ...V) (int64, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	members, err := encodeValues(&s.object, values)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.ZRem(ctx, s.name, members...).Result()
+}
+
+/**
+ * 未过期的元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (s *SetCache[V]) Size(ctx context.Context) (int64, error) {
+	return setCacheSizeScripter.Run(ctx, s.r, []string{s.name}).Int64()
+}
+
+/**
+ * 读取全部未过期的元素
+ *
+ * return: []V
+ * return: error
+ */
+func (s *SetCache[V]) ReadAll(ctx context.Context) ([]V, error) {
+	return decodeValues[V](s.codec)(stringSlice(setCacheReadAllScripter.Run(ctx, s.r, []string{s.name})))
+}
+
+/**
+ * 获取迭代器,只返回未过期的元素
+ *
+ * param: int64 count 每次ZSCAN的数量,小于等于0时使用SCAN命令默认的数量
+ * return: *SetIterator[V]
+ */
+func (s *SetCache[V]) Iterator(count int64) *SetIterator[V] {
+	if count <= 0 {
+		count = setCacheScanCount
+	}
+	it := &SetIterator[V]{o: &s.object}
+	it.scan.scan = func(ctx context.Context, cursor uint64) ([]string, uint64, error) {
+		res, err := stringSlice(setCacheScanScripter.Run(ctx, s.r, []string{s.name}, cursor, count))
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(res) == 0 {
+			return nil, 0, nil
+		}
+		next, err := strconv.ParseUint(res[0], 10, 64)
+		return res[1:], next, err
+	}
+	return it
+}
+
+/**
+ * 停止后台清理任务,可以重复调用
+ */
+func (s *SetCache[V]) Close() {
+	s.StopEviction()
+}
+
+func (s *SetCache[V]) evict(ctx context.Context) error {
+	return setCacheEvictScripter.Run(ctx, s.r, []string{s.name}).Err()
+}
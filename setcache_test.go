@@ -0,0 +1,146 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestSetCache(t *testing.T) *SetCache[string] {
+	t.Helper()
+	s := GetSetCache[string](testRedis(t), "cache")
+	if _, err := s.Delete(context.Background()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	return s
+}
+
+func TestSetCache_Add(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSetCache(t)
+	tests := []struct {
+		name  string
+		value string
+		ttl   time.Duration
+		want  bool
+	}{
+		{"new", "a", 100 * time.Millisecond, true},
+		{"existing", "a", 100 * time.Millisecond, false},
+		{"never expires", "b", 0, true},
+		{"short ttl", "c", 100 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Add(ctx, tt.value, tt.ttl)
+			if err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if n, err := s.Size(ctx); err != nil || n != 3 {
+		t.Errorf("Size() = %v, %v, want 3", n, err)
+	}
+	// 续期a,c保持原来的过期时间
+	if _, err := s.Add(ctx, "a", time.Minute); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	contains := []struct {
+		value string
+		want  bool
+	}{
+		{"a", true},
+		{"b", true},
+		{"c", false},
+		{"d", false},
+	}
+	for _, tt := range contains {
+		if got, err := s.Contains(ctx, tt.value); err != nil || got != tt.want {
+			t.Errorf("Contains(%v) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+	if n, err := s.Size(ctx); err != nil || n != 2 {
+		t.Errorf("Size() = %v, %v, want 2", n, err)
+	}
+	got, err := s.ReadAll(ctx)
+	sort.Strings(got)
+	if want := []string{"a", "b"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAll() = %v, %v, want %v", got, err, want)
+	}
+	// 已经过期的元素再次添加视为新增
+	if ok, err := s.Add(ctx, "c", 0); err != nil || !ok {
+		t.Errorf("Add() of an expired value = %v, %v, want true", ok, err)
+	}
+}
+
+func TestSetCache_Iterator(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSetCache(t)
+	var want []string
+	for i := 0; i < 30; i++ {
+		v := "v" + strconv.Itoa(i)
+		ttl := time.Duration(0)
+		if i%3 == 0 {
+			ttl = 50 * time.Millisecond
+		} else {
+			want = append(want, v)
+		}
+		if _, err := s.Add(ctx, v, ttl); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	sort.Strings(want)
+	time.Sleep(100 * time.Millisecond)
+	for _, count := range []int64{0, 1, 7, 100} {
+		var got []string
+		it := s.Iterator(count)
+		for it.Next(ctx) {
+			got = append(got, it.Value())
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Iterator(%v) error = %v", count, err)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Iterator(%v) = %v, want %v", count, got, want)
+		}
+	}
+}
+
+func TestSetCache_evict(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSetCache(t)
+	if _, err := s.Add(ctx, "a", 50*time.Millisecond); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := s.Add(ctx, "b", 0); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.evict(ctx); err != nil {
+		t.Fatalf("evict() error = %v", err)
+	}
+	if n, err := s.r.ZCard(ctx, s.name).Result(); err != nil || n != 2 {
+		t.Errorf("ZCard() before expiry = %v, %v, want 2", n, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := s.evict(ctx); err != nil {
+		t.Fatalf("evict() error = %v", err)
+	}
+	got, err := s.r.ZRange(ctx, s.name, 0, -1).Result()
+	if err != nil {
+		t.Fatalf("ZRange() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("members after evict = %v, want only the value without ttl", got)
+	}
+	// 删除了过期元素后Remove只统计剩下的元素
+	if n, err := s.Remove(ctx, "a", "b"); err != nil || n != 1 {
+		t.Errorf("Remove() = %v, %v, want 1", n, err)
+	}
+}
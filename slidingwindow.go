@@ -0,0 +1,211 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+	"github.com/hashicorp/go-uuid"
+)
+
+const (
+	SlidingLogPrefix     = "slidinglog:"
+	SlidingCounterPrefix = "slidingcounter:"
+
+	// 滑动窗口日志: 每次请求作为一个成员记录在有序集合中,分值为请求时间
+	// KEYS[1] 有序集合 ARGV[1] limit ARGV[2] 窗口毫秒数 ARGV[3] 本次请求数 ARGV[4] 成员前缀
+	// 返回 {是否允许, 剩余配额, 需要等待的毫秒数, 最早一条记录过期的毫秒数}
+	SlidingLogScript = `
+redis.replicate_commands()
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('zremrangebyscore', KEYS[1], '-inf', now - window)
+local count = redis.call('zcard', KEYS[1])
+if count + n <= limit then
+	for i = 1, n do
+		redis.call('zadd', KEYS[1], now, ARGV[4] .. ':' .. i)
+	end
+	redis.call('pexpire', KEYS[1], window)
+	local oldest = redis.call('zrange', KEYS[1], 0, 0, 'withscores')
+	return {1, limit - count - n, 0, tonumber(oldest[2]) + window - now}
+end
+local remaining = limit - count
+if remaining < 0 then
+	remaining = 0
+end
+local idx = count + n - limit - 1
+local entry = redis.call('zrange', KEYS[1], idx, idx, 'withscores')
+local oldest = redis.call('zrange', KEYS[1], 0, 0, 'withscores')
+return {0, remaining, tonumber(entry[2]) + window - now, tonumber(oldest[2]) + window - now}
+`
+
+	// 滑动窗口计数: 只保存当前窗口和上一个窗口的计数,按当前窗口已过去的比例估算请求数
+	// KEYS[1] 计数hash ARGV[1] limit ARGV[2] 窗口毫秒数 ARGV[3] 本次请求数
+	// 返回 {是否允许, 剩余配额, 需要等待的毫秒数, 当前窗口结束的毫秒数}
+	SlidingCounterScript = `
+redis.replicate_commands()
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local idx = math.floor(now / window)
+local elapsed = now - idx * window
+local cur = tonumber(redis.call('hget', KEYS[1], idx) or '0')
+local prev = tonumber(redis.call('hget', KEYS[1], idx - 1) or '0')
+local estimate = prev * (window - elapsed) / window + cur
+if estimate + n <= limit then
+	redis.call('hincrby', KEYS[1], idx, n)
+	for _, field in ipairs(redis.call('hkeys', KEYS[1])) do
+		if tonumber(field) < idx - 1 then
+			redis.call('hdel', KEYS[1], field)
+		end
+	end
+	redis.call('pexpire', KEYS[1], window * 2)
+	return {1, math.floor(limit - estimate - n), 0, window - elapsed}
+end
+local remaining = math.floor(limit - estimate)
+if remaining < 0 then
+	remaining = 0
+end
+local wait
+if cur + n <= limit then
+	wait = (window - elapsed) - (limit - cur - n) * window / prev
+else
+	wait = (window - elapsed) + math.max(0, window - (limit - n) * window / cur)
+end
+return {0, remaining, math.ceil(wait), window - elapsed}
+`
+)
+
+var (
+	slidingLogScripter     = gredis.NewScript(SlidingLogScript)
+	slidingCounterScripter = gredis.NewScript(SlidingCounterScript)
+)
+
+// RateLimitResult 限流结果,可直接用于设置 RateLimit-* 和 Retry-After 响应头
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	RetryAfter time.Duration // 被拒绝时需要等待的时间
+	ResetAfter time.Duration // 配额开始恢复的时间
+}
+
+// SlidingWindowLog 基于有序集合的精确滑动窗口限流器
+type SlidingWindowLog struct {
+	r      *Redis
+	name   string
+	limit  int64
+	window time.Duration
+}
+
+// SlidingWindowCounter 基于前后两个固定窗口计数的近似滑动窗口限流器,每个限流器只占用一个很小的hash
+type SlidingWindowCounter struct {
+	r      *Redis
+	name   string
+	limit  int64
+	window time.Duration
+}
+
+/**
+ * 获取滑动窗口日志限流器
+ *
+ * param: string        name
+ * param: int64         limit  窗口内允许的请求数
+ * param: time.Duration window
+ * return: *SlidingWindowLog
+ */
+func (r *Redis) GetSlidingWindowLog(name string, limit int64, window time.Duration) *SlidingWindowLog {
+	return &SlidingWindowLog{r: r, name: r.namespaced(SlidingLogPrefix + name), limit: limit, window: window}
+}
+
+/**
+ * 获取滑动窗口计数限流器
+ *
+ * param: string        name
+ * param: int64         limit  窗口内允许的请求数
+ * param: time.Duration window
+ * return: *SlidingWindowCounter
+ */
+func (r *Redis) GetSlidingWindowCounter(name string, limit int64, window time.Duration) *SlidingWindowCounter {
+	return &SlidingWindowCounter{r: r, name: r.namespaced(SlidingCounterPrefix + name), limit: limit, window: window}
+}
+
+/**
+ * 尝试通过一次请求
+ *
+ * return: *RateLimitResult
+ * return: error
+ */
+func (l *SlidingWindowLog) Allow(ctx context.Context) (*RateLimitResult, error) {
+	return l.AllowN(ctx, 1)
+}
+
+/**
+ * 尝试通过n次请求,只有全部允许时才会记录
+ *
+ * param: int64 n
+ * return: *RateLimitResult
+ * return: error
+ */
+func (l *SlidingWindowLog) AllowN(ctx context.Context, n int64) (*RateLimitResult, error) {
+	if err := checkSlidingWindow(l.limit, l.window, n); err != nil {
+		return nil, err
+	}
+	prefix, _ := uuid.GenerateUUID()
+	cmd := slidingLogScripter.Run(ctx, l.r, []string{l.name}, l.limit, l.window.Milliseconds(), n, prefix)
+	return newRateLimitResult(cmd, l.limit)
+}
+
+/**
+ * 尝试通过一次请求
+ *
+ * return: *RateLimitResult
+ * return: error
+ */
+func (l *SlidingWindowCounter) Allow(ctx context.Context) (*RateLimitResult, error) {
+	return l.AllowN(ctx, 1)
+}
+
+/**
+ * 尝试通过n次请求,只有全部允许时才会计数
+ *
+ * param: int64 n
+ * return: *RateLimitResult
+ * return: error
+ */
+func (l *SlidingWindowCounter) AllowN(ctx context.Context, n int64) (*RateLimitResult, error) {
+	if err := checkSlidingWindow(l.limit, l.window, n); err != nil {
+		return nil, err
+	}
+	cmd := slidingCounterScripter.Run(ctx, l.r, []string{l.name}, l.limit, l.window.Milliseconds(), n)
+	return newRateLimitResult(cmd, l.limit)
+}
+
+func checkSlidingWindow(limit int64, window time.Duration, n int64) error {
+	if n <= 0 || limit <= 0 || window < time.Millisecond {
+		return ErrInvalidPermits
+	}
+	if n > limit {
+		return ErrPermitsExceedRate
+	}
+	return nil
+}
+
+func newRateLimitResult(cmd *gredis.Cmd, limit int64) (*RateLimitResult, error) {
+	res, err := int64Slice(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimitResult{
+		Allowed:    res[0] == 1,
+		Limit:      limit,
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+func TestCheckSlidingWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int64
+		window time.Duration
+		n      int64
+		want   error
+	}{
+		{"ok", 10, time.Second, 1, nil},
+		{"whole limit", 10, time.Second, 10, nil},
+		{"zero n", 10, time.Second, 0, ErrInvalidPermits},
+		{"zero limit", 0, time.Second, 1, ErrInvalidPermits},
+		{"short window", 10, time.Microsecond, 1, ErrInvalidPermits},
+		{"exceed limit", 10, time.Second, 11, ErrPermitsExceedRate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSlidingWindow(tt.limit, tt.window, tt.n); err != tt.want {
+				t.Errorf("checkSlidingWindow() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRateLimitResult(t *testing.T) {
+	errScript := errors.New("script failed")
+	tests := []struct {
+		name    string
+		val     interface{}
+		err     error
+		want    RateLimitResult
+		wantErr error
+	}{
+		{"allowed", []interface{}{int64(1), int64(4), int64(0), int64(1500)}, nil,
+			RateLimitResult{Allowed: true, Limit: 5, Remaining: 4, ResetAfter: 1500 * time.Millisecond}, nil},
+		{"denied", []interface{}{int64(0), int64(0), int64(200), int64(1000)}, nil,
+			RateLimitResult{Limit: 5, RetryAfter: 200 * time.Millisecond, ResetAfter: time.Second}, nil},
+		{"error", nil, errScript, RateLimitResult{}, errScript},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newRateLimitResult(gredis.NewCmdResult(tt.val, tt.err), 5)
+			if err != tt.wantErr {
+				t.Fatalf("newRateLimitResult() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("newRateLimitResult() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
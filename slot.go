@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"strings"
+)
+
+const slotCount = 16384
+
+var crc16Table [256]uint16
+
+func init() {
+	for i := range crc16Table {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		crc16Table[i] = crc
+	}
+}
+
+/**
+ * key中参与hash的部分,有 {hashtag} 时只使用hashtag
+ *
+ * param: string key
+ * return: string
+ */
+func hashTag(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key[start+1 : start+1+end]
+		}
+	}
+	return key
+}
+
+/**
+ * 计算key所在的集群slot,支持 {hashtag}
+ *
+ * param: string key
+ * return: int
+ */
+func keySlot(key string) int {
+	key = hashTag(key)
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^key[i]]
+	}
+	return int(crc) % slotCount
+}
+
+/**
+ * 按slot对key分组,同一组的key可以在一个命令中执行
+ * 集群模式下按slot分组,Ring按hashtag分组,单机模式下只有一组
+ *
+ * param: []string keys
+ * return: [][]string
+ */
+func (r *Redis) groupBySlot(keys []string) [][]string {
+	if !r.isCluster() && !r.isRing() {
+		return [][]string{keys}
+	}
+	index := make(map[interface{}]int)
+	var groups [][]string
+	for _, key := range keys {
+		slot := r.slotOf(key)
+		i, ok := index[slot]
+		if !ok {
+			i = len(groups)
+			index[slot] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], key)
+	}
+	return groups
+}
+
+/**
+ * 检查所有key是否可以在一个命令中执行
+ * 集群模式下要求位于同一个slot,Ring无法得知key所在的节点,要求hashtag相同
+ *
+ * param: ...string keys
+ * return: error
+ */
+func (r *Redis) checkSameSlot(keys ...string) error {
+	if !r.isCluster() && !r.isRing() || len(keys) == 0 {
+		return nil
+	}
+	slot := r.slotOf(keys[0])
+	for _, key := range keys[1:] {
+		if r.slotOf(key) != slot {
+			return ErrCrossSlot
+		}
+	}
+	return nil
+}
+
+func (r *Redis) slotOf(key string) interface{} {
+	if r.isRing() {
+		return hashTag(key)
+	}
+	return keySlot(key)
+}
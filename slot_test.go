@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+func TestKeySlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"", 0},
+		{"123456789", 12739},
+		{"foo", 12182},
+		{"{user1000}.following", 3443},
+		{"{user1000}.followers", 3443},
+		{"foo{}{bar}", 8363},
+		{"{}", 15257},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := keySlot(tt.key); got != tt.want {
+				t.Errorf("keySlot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashTag(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"foo", "foo"},
+		{"{user1000}.following", "user1000"},
+		{"foo{}{bar}", "foo{}{bar}"},
+		{"foo{bar}{zap}", "bar"},
+		{"{", "{"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := hashTag(tt.key); got != tt.want {
+				t.Errorf("hashTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedis_groupBySlot(t *testing.T) {
+	ring := gredis.NewRing(&gredis.RingOptions{Addrs: map[string]string{"a": "127.0.0.1:1"}})
+	defer ring.Close()
+	cluster := gredis.NewClusterClient(&gredis.ClusterOptions{Addrs: []string{"127.0.0.1:1"}})
+	defer cluster.Close()
+	alone := gredis.NewClient(&gredis.Options{Addr: "127.0.0.1:1"})
+	defer alone.Close()
+	keys := []string{"{a}1", "{a}2", "b", "c"}
+	tests := []struct {
+		name   string
+		client gredis.UniversalClient
+		want   int
+	}{
+		{"alone", alone, 1},
+		{"cluster", cluster, 3},
+		{"ring", ring, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Redis{UniversalClient: tt.client}
+			if got := len(r.groupBySlot(keys)); got != tt.want {
+				t.Errorf("groupBySlot() groups = %v, want %v", got, tt.want)
+			}
+			wantErr := tt.want > 1
+			if err := r.checkSameSlot(keys...); (err != nil) != wantErr {
+				t.Errorf("checkSameSlot() error = %v, wantErr %v", err, wantErr)
+			}
+			if err := r.checkSameSlot("{a}1", "{a}2"); err != nil {
+				t.Errorf("checkSameSlot() same tag error = %v", err)
+			}
+		})
+	}
+}
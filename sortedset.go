@@ -0,0 +1,593 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	// KEYS[1] 目标集合 KEYS[2..] 参与计算的集合,KEYS[1]可以同时是任意一个参与计算的集合,因此先算出结果再删除KEYS[1]
+	SortedSetDiffScript = `
+local entries = redis.call('zrange', KEYS[2], 0, -1, 'withscores')
+local kept = {}
+for i = 1, #entries, 2 do
+	local keep = true
+	for j = 3, #KEYS do
+		if redis.call('zscore', KEYS[j], entries[i]) ~= false then
+			keep = false
+			break
+		end
+	end
+	if keep then
+		kept[#kept + 1] = entries[i + 1]
+		kept[#kept + 1] = entries[i]
+	end
+end
+redis.call('del', KEYS[1])
+for i = 1, #kept, 2 do
+	redis.call('zadd', KEYS[1], kept[i], kept[i + 1])
+end
+return redis.call('zcard', KEYS[1])
+`
+)
+
+var (
+	sortedSetDiffScripter = gredis.NewScript(SortedSetDiffScript)
+)
+
+// ScoredSortedSet 基于有序集合的分布式集合,按分数排序,元素使用codec编码
+type ScoredSortedSet[V any] struct {
+	object
+}
+
+// ScoredEntry 元素及其分数
+type ScoredEntry[V any] struct {
+	Score float64
+	Value V
+}
+
+// ScoredSortedSetIterator 基于ZSCAN遍历有序集合,遍历期间有修改时同一个元素可能返回多次
+type ScoredSortedSetIterator[V any] struct {
+	s     *ScoredSortedSet[V]
+	scan  scanIterator
+	entry ScoredEntry[V]
+	err   error
+}
+
+/**
+ * 获取ScoredSortedSet
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *ScoredSortedSet[V]
+ */
+func GetScoredSortedSet[V any](r *Redis, name string) *ScoredSortedSet[V] {
+	return GetScoredSortedSetWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的ScoredSortedSet
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *ScoredSortedSet[V]
+ */
+func GetScoredSortedSetWithCodec[V any](r *Redis, name string, c codec.Codec) *ScoredSortedSet[V] {
+	return &ScoredSortedSet[V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 添加元素,已经存在时更新分数
+ *
+ * param: float64 score
+ * param: V       value
+ * return: bool 是否是新增的元素
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Add(ctx context.Context, score float64, value V) (bool, error) {
+	n, err := s.AddAll(ctx, ScoredEntry[V]{Score: score, Value: value})
+	return n == 1, err
+}
+
+/**
+ * 批量添加元素,已经存在时更新分数
+ *
+ * param: ...ScoredEntry[V] entries
+ * return: int64 新增的数量
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) AddAll(ctx context.Context, entries ...ScoredEntry[V]) (int64, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	members, err := s.members(entries)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.ZAdd(ctx, s.name, members...).Result()
+}
+
+/**
+ * 元素不存在时添加
+ *
+ * param: float64 score
+ * param: V       value
+ * return: bool 是否添加成功
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) TryAdd(ctx context.Context, score float64, value V) (bool, error) {
+	members, err := s.members([]ScoredEntry[V]{{Score: score, Value: value}})
+	if err != nil {
+		return false, err
+	}
+	n, err := s.r.ZAddNX(ctx, s.name, members...).Result()
+	return n == 1, err
+}
+
+/**
+ * 给元素的分数加上delta,元素不存在时以delta为分数添加
+ *
+ * param: V       value
+ * param: float64 delta
+ * return: float64 新的分数
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) AddScore(ctx context.Context, value V, delta float64) (float64, error) {
+	member, err := s.encode(value)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.ZIncrBy(ctx, s.name, delta, member).Result()
+}
+
+/**
+ * 删除元素
+ *
+ * param: ...V values
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Remove(ctx context.Context, values ...V) (int64, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	members, err := encodeValues(&s.object, values)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.ZRem(ctx, s.name, members...).Result()
+}
+
+/**
+ * 是否包含元素
+ *
+ * param: V value
+ * return: bool
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Contains(ctx context.Context, value V) (bool, error) {
+	_, ok, err := s.Score(ctx, value)
+	return ok, err
+}
+
+/**
+ * 元素的分数
+ *
+ * param: V value
+ * return: float64
+ * return: bool 元素是否存在
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Score(ctx context.Context, value V) (float64, bool, error) {
+	member, err := s.encode(value)
+	if err != nil {
+		return 0, false, err
+	}
+	return optionalResult(s.r.ZScore(ctx, s.name, member).Result())
+}
+
+/**
+ * 元素按分数从小到大的排名,从0开始
+ *
+ * param: V value
+ * return: int64
+ * return: bool 元素是否存在
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Rank(ctx context.Context, value V) (int64, bool, error) {
+	member, err := s.encode(value)
+	if err != nil {
+		return 0, false, err
+	}
+	return optionalResult(s.r.ZRank(ctx, s.name, member).Result())
+}
+
+/**
+ * 元素按分数从大到小的排名,从0开始
+ *
+ * param: V value
+ * return: int64
+ * return: bool 元素是否存在
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) RevRank(ctx context.Context, value V) (int64, bool, error) {
+	member, err := s.encode(value)
+	if err != nil {
+		return 0, false, err
+	}
+	return optionalResult(s.r.ZRevRank(ctx, s.name, member).Result())
+}
+
+/**
+ * 元素数量
+ *
+ * return: int64
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Size(ctx context.Context) (int64, error) {
+	return s.r.ZCard(ctx, s.name).Result()
+}
+
+/**
+ * 分数在区间内的元素数量
+ *
+ * param: float64 min
+ * param: bool    minInclusive
+ * param: float64 max
+ * param: bool    maxInclusive
+ * return: int64
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Count(ctx context.Context, min float64, minInclusive bool, max float64, maxInclusive bool) (int64, error) {
+	return s.r.ZCount(ctx, s.name, scoreBound(min, minInclusive), scoreBound(max, maxInclusive)).Result()
+}
+
+/**
+ * 按排名读取元素,分数从小到大,start和end可以为负数表示从末尾开始
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: []V
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) ValueRange(ctx context.Context, start, end int64) ([]V, error) {
+	return decodeValues[V](s.codec)(s.r.ZRange(ctx, s.name, start, end).Result())
+}
+
+/**
+ * 按排名读取元素,分数从大到小
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: []V
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) ValueRangeReversed(ctx context.Context, start, end int64) ([]V, error) {
+	return decodeValues[V](s.codec)(s.r.ZRevRange(ctx, s.name, start, end).Result())
+}
+
+/**
+ * 按排名读取元素及分数,分数从小到大
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: []ScoredEntry[V]
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) EntryRange(ctx context.Context, start, end int64) ([]ScoredEntry[V], error) {
+	return s.entries(s.r.ZRangeWithScores(ctx, s.name, start, end).Result())
+}
+
+/**
+ * 按排名读取元素及分数,分数从大到小
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: []ScoredEntry[V]
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) EntryRangeReversed(ctx context.Context, start, end int64) ([]ScoredEntry[V], error) {
+	return s.entries(s.r.ZRevRangeWithScores(ctx, s.name, start, end).Result())
+}
+
+/**
+ * 按分数区间读取元素,分数从小到大
+ *
+ * param: float64 min
+ * param: bool    minInclusive
+ * param: float64 max
+ * param: bool    maxInclusive
+ * param: int64   offset
+ * param: int64   count 为0时不限制
+ * return: []V
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) ValueRangeByScore(ctx context.Context, min float64, minInclusive bool, max float64, maxInclusive bool, offset, count int64) ([]V, error) {
+	by := scoreRange(min, minInclusive, max, maxInclusive, offset, count)
+	return decodeValues[V](s.codec)(s.r.ZRangeByScore(ctx, s.name, by).Result())
+}
+
+/**
+ * 按分数区间读取元素,分数从大到小
+ *
+ * param: float64 min
+ * param: bool    minInclusive
+ * param: float64 max
+ * param: bool    maxInclusive
+ * param: int64   offset
+ * param: int64   count 为0时不限制
+ * return: []V
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) ValueRangeByScoreReversed(ctx context.Context, min float64, minInclusive bool, max float64, maxInclusive bool, offset, count int64) ([]V, error) {
+	by := scoreRange(min, minInclusive, max, maxInclusive, offset, count)
+	return decodeValues[V](s.codec)(s.r.ZRevRangeByScore(ctx, s.name, by).Result())
+}
+
+/**
+ * 按分数区间读取元素及分数,分数从小到大
+ *
+ * param: float64 min
+ * param: bool    minInclusive
+ * param: float64 max
+ * param: bool    maxInclusive
+ * param: int64   offset
+ * param: int64   count 为0时不限制
+ * return: []ScoredEntry[V]
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) EntryRangeByScore(ctx context.Context, min float64, minInclusive bool, max float64, maxInclusive bool, offset, count int64) ([]ScoredEntry[V], error) {
+	by := scoreRange(min, minInclusive, max, maxInclusive, offset, count)
+	return s.entries(s.r.ZRangeByScoreWithScores(ctx, s.name, by).Result())
+}
+
+/**
+ * 删除排名区间内的元素
+ *
+ * param: int64 start
+ * param: int64 end
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) RemoveRangeByRank(ctx context.Context, start, end int64) (int64, error) {
+	return s.r.ZRemRangeByRank(ctx, s.name, start, end).Result()
+}
+
+/**
+ * 删除分数区间内的元素
+ *
+ * param: float64 min
+ * param: bool    minInclusive
+ * param: float64 max
+ * param: bool    maxInclusive
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) RemoveRangeByScore(ctx context.Context, min float64, minInclusive bool, max float64, maxInclusive bool) (int64, error) {
+	return s.r.ZRemRangeByScore(ctx, s.name, scoreBound(min, minInclusive), scoreBound(max, maxInclusive)).Result()
+}
+
+/**
+ * 分数最小的元素
+ *
+ * return: ScoredEntry[V]
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) First(ctx context.Context) (ScoredEntry[V], bool, error) {
+	return s.first(s.EntryRange(ctx, 0, 0))
+}
+
+/**
+ * 分数最大的元素
+ *
+ * return: ScoredEntry[V]
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Last(ctx context.Context) (ScoredEntry[V], bool, error) {
+	return s.first(s.EntryRangeReversed(ctx, 0, 0))
+}
+
+/**
+ * 删除并返回分数最小的元素
+ *
+ * return: ScoredEntry[V]
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) PollFirst(ctx context.Context) (ScoredEntry[V], bool, error) {
+	return s.first(s.entries(s.r.ZPopMin(ctx, s.name).Result()))
+}
+
+/**
+ * 删除并返回分数最大的元素
+ *
+ * return: ScoredEntry[V]
+ * return: bool 集合为空时返回false
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) PollLast(ctx context.Context) (ScoredEntry[V], bool, error) {
+	return s.first(s.entries(s.r.ZPopMax(ctx, s.name).Result()))
+}
+
+/**
+ * 把当前集合和names的并集保存到dest,相同元素的分数相加,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Union(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.ZUnionStore(ctx, dest, &gredis.ZStore{Keys: keys}).Result()
+}
+
+/**
+ * 把当前集合和names的交集保存到dest,相同元素的分数相加,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Intersect(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	return s.r.ZInterStore(ctx, dest, &gredis.ZStore{Keys: keys}).Result()
+}
+
+/**
+ * 把当前集合与names的差集保存到dest,保留当前集合中的分数,集群模式下所有集合需要位于同一个slot
+ *
+ * param: string    dest
+ * param: ...string names
+ * return: int64 保存后的元素数量
+ * return: error
+ */
+func (s *ScoredSortedSet[V]) Diff(ctx context.Context, dest string, names ...string) (int64, error) {
+	dest, keys, err := s.withDest(dest, names)
+	if err != nil {
+		return 0, err
+	}
+	return sortedSetDiffScripter.Run(ctx, s.r, append([]string{dest}, keys...)).Int64()
+}
+
+/**
+ * 获取迭代器
+ *
+ * param: int64 count 每次ZSCAN的数量
+ * return: *ScoredSortedSetIterator[V]
+ */
+func (s *ScoredSortedSet[V]) Iterator(count int64) *ScoredSortedSetIterator[V] {
+	it := &ScoredSortedSetIterator[V]{s: s}
+	it.scan.scan = func(ctx context.Context, cursor uint64) ([]string, uint64, error) {
+		return s.r.ZScan(ctx, s.name, cursor, "", count).Result()
+	}
+	return it
+}
+
+func (s *ScoredSortedSet[V]) members(entries []ScoredEntry[V]) ([]*gredis.Z, error) {
+	members := make([]*gredis.Z, len(entries))
+	for i, entry := range entries {
+		member, err := s.encode(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = &gredis.Z{Score: entry.Score, Member: member}
+	}
+	return members, nil
+}
+
+func (s *ScoredSortedSet[V]) entries(zs []gredis.Z, err error) ([]ScoredEntry[V], error) {
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ScoredEntry[V], len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		if entries[i].Value, err = decodeAs[V](s.codec, member); err != nil {
+			return nil, err
+		}
+		entries[i].Score = z.Score
+	}
+	return entries, nil
+}
+
+func (s *ScoredSortedSet[V]) first(entries []ScoredEntry[V], err error) (ScoredEntry[V], bool, error) {
+	if err != nil || len(entries) == 0 {
+		return ScoredEntry[V]{}, false, err
+	}
+	return entries[0], true, nil
+}
+
+/**
+ * 把redis.Nil转换为不存在
+ *
+ * param: T     v
+ * param: error err
+ * return: T
+ * return: bool
+ * return: error
+ */
+func optionalResult[T any](v T, err error) (T, bool, error) {
+	if err == gredis.Nil {
+		return v, false, nil
+	}
+	return v, err == nil, err
+}
+
+func scoreBound(score float64, inclusive bool) string {
+	bound := strconv.FormatFloat(score, 'g', -1, 64)
+	if !inclusive {
+		bound = "(" + bound
+	}
+	return bound
+}
+
+func scoreRange(min float64, minInclusive bool, max float64, maxInclusive bool, offset, count int64) *gredis.ZRangeBy {
+	by := &gredis.ZRangeBy{Min: scoreBound(min, minInclusive), Max: scoreBound(max, maxInclusive), Offset: offset, Count: count}
+	if count == 0 && offset > 0 {
+		by.Count = -1
+	}
+	return by
+}
+
+/**
+ * 移动到下一个元素
+ *
+ * return: bool 是否还有元素
+ */
+func (it *ScoredSortedSetIterator[V]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	items := it.scan.next(ctx, 2)
+	if items == nil {
+		it.err = it.scan.err
+		return false
+	}
+	if it.entry.Value, it.err = decodeAs[V](it.s.codec, items[0]); it.err != nil {
+		return false
+	}
+	it.entry.Score, it.err = strconv.ParseFloat(items[1], 64)
+	return it.err == nil
+}
+
+/**
+ * 当前元素
+ *
+ * return: V
+ */
+func (it *ScoredSortedSetIterator[V]) Value() V {
+	return it.entry.Value
+}
+
+/**
+ * 当前元素的分数
+ *
+ * return: float64
+ */
+func (it *ScoredSortedSetIterator[V]) Score() float64 {
+	return it.entry.Score
+}
+
+/**
+ * 遍历过程中的错误
+ *
+ * return: error
+ */
+func (it *ScoredSortedSetIterator[V]) Err() error {
+	return it.err
+}
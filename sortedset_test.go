@@ -0,0 +1,114 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+func TestScoreRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		min          float64
+		minInclusive bool
+		max          float64
+		maxInclusive bool
+		offset       int64
+		count        int64
+		want         gredis.ZRangeBy
+	}{
+		{"inclusive", 1, true, 2.5, true, 0, 0, gredis.ZRangeBy{Min: "1", Max: "2.5"}},
+		{"exclusive", -1, false, 1e21, false, 0, 0, gredis.ZRangeBy{Min: "(-1", Max: "(1e+21"}},
+		{"limit", 0, true, 10, false, 2, 3, gredis.ZRangeBy{Min: "0", Max: "(10", Offset: 2, Count: 3}},
+		{"offset-only", 0, true, 10, true, 2, 0, gredis.ZRangeBy{Min: "0", Max: "10", Offset: 2, Count: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreRange(tt.min, tt.minInclusive, tt.max, tt.maxInclusive, tt.offset, tt.count)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("scoreRange() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObject_withDest(t *testing.T) {
+	cluster := gredis.NewClusterClient(&gredis.ClusterOptions{Addrs: []string{"127.0.0.1:1"}})
+	defer cluster.Close()
+	tests := []struct {
+		name     string
+		r        *Redis
+		dest     string
+		names    []string
+		wantDest string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{"alone", &Redis{}, "d", []string{"b"}, "d", []string{"{s}", "b"}, false},
+		{"cluster-same-slot", &Redis{UniversalClient: cluster}, "{s}:d", []string{"{s}:b"}, "{s}:d", []string{"{s}", "{s}:b"}, false},
+		{"cluster-cross-slot-dest", &Redis{UniversalClient: cluster}, "d", []string{"{s}:b"}, "", nil, true},
+		{"cluster-cross-slot-source", &Redis{UniversalClient: cluster}, "{s}:d", []string{"b"}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := object{r: tt.r, name: "{s}"}
+			dest, keys, err := o.withDest(tt.dest, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("withDest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if dest != tt.wantDest || !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("withDest() = %v, %v, want %v, %v", dest, keys, tt.wantDest, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestScoredSortedSet_Diff(t *testing.T) {
+	ctx := context.Background()
+	r := testRedis(t)
+	tests := []struct {
+		name  string
+		dest  string
+		names []string
+		want  []ScoredEntry[string]
+	}{
+		{"new dest", "{s}:d", []string{"{s}:b", "{s}:c"}, []ScoredEntry[string]{{1, "a"}, {4, "d"}}},
+		{"dest is source", "{s}", []string{"{s}:b"}, []ScoredEntry[string]{{1, "a"}, {3, "c"}, {4, "d"}}},
+		{"dest is subtracted", "{s}:b", []string{"{s}:c", "{s}:b"}, []ScoredEntry[string]{{1, "a"}, {4, "d"}}},
+		{"dest is only subtracted", "{s}:c", []string{"{s}:c"}, []ScoredEntry[string]{{1, "a"}, {2, "b"}, {4, "d"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GetScoredSortedSet[string](r, "{s}")
+			b := GetScoredSortedSet[string](r, "{s}:b")
+			c := GetScoredSortedSet[string](r, "{s}:c")
+			for _, set := range []*ScoredSortedSet[string]{s, b, c, GetScoredSortedSet[string](r, "{s}:d")} {
+				if _, err := set.Delete(ctx); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+			}
+			if _, err := s.AddAll(ctx, ScoredEntry[string]{1, "a"}, ScoredEntry[string]{2, "b"}, ScoredEntry[string]{3, "c"}, ScoredEntry[string]{4, "d"}); err != nil {
+				t.Fatalf("AddAll() error = %v", err)
+			}
+			if _, err := b.AddAll(ctx, ScoredEntry[string]{0, "b"}, ScoredEntry[string]{0, "x"}); err != nil {
+				t.Fatalf("AddAll() error = %v", err)
+			}
+			if _, err := c.AddAll(ctx, ScoredEntry[string]{0, "c"}); err != nil {
+				t.Fatalf("AddAll() error = %v", err)
+			}
+			n, err := s.Diff(ctx, tt.dest, tt.names...)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			got, err := GetScoredSortedSet[string](r, tt.dest).EntryRange(ctx, 0, -1)
+			if err != nil {
+				t.Fatalf("EntryRange() error = %v", err)
+			}
+			if n != int64(len(tt.want)) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, %v, want %v", n, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,533 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+const (
+	DefaultStreamClaimMinIdle = 30 * time.Second
+	DefaultStreamClaimCount   = 100
+
+	// 消息内容保存在这个字段中
+	streamValueField = "value"
+
+	// KEYS[1] stream KEYS[2] 死信stream ARGV[1] 消费组 ARGV[2] 最小空闲毫秒数 ARGV[3...] 消息id
+	// 把消息复制到死信stream后确认,死信消息保留原始字段并增加id和消费组字段
+	// 读取XPENDING之后消息可能已经被确认或者重新投递,空闲时间不足或者已经确认的消息跳过
+	StreamDeadLetterScript = `
+local n = 0
+for i = 3, #ARGV do
+	local p = redis.call('xpending', KEYS[1], ARGV[1], ARGV[i], ARGV[i], 1)
+	if #p > 0 and tonumber(p[1][3]) >= tonumber(ARGV[2]) then
+		local msg = redis.call('xrange', KEYS[1], ARGV[i], ARGV[i])
+		if #msg > 0 then
+			redis.call('xadd', KEYS[2], '*', 'id', ARGV[i], 'group', ARGV[1], unpack(msg[1][2]))
+		end
+		redis.call('xack', KEYS[1], ARGV[1], ARGV[i])
+		n = n + 1
+	end
+end
+return n
+`
+)
+
+var streamDeadLetterScripter = gredis.NewScript(StreamDeadLetterScript)
+
+// Stream 基于redis stream的消息流,每条消息的内容使用codec编码后保存在value字段中
+type Stream[V any] struct {
+	object
+}
+
+// StreamMessage stream中的一条消息
+type StreamMessage[V any] struct {
+	ID    string
+	Value V
+}
+
+// StreamAddOptions 添加消息时的参数
+type StreamAddOptions struct {
+	ID     string // 消息id,为空时自动生成
+	MaxLen int64  // 大于0时只保留最新的MaxLen条消息
+	MinID  string // 不为空时删除id小于MinID的消息,需要redis 6.2
+	Approx bool   // 使用 ~ 近似裁剪,性能更好但可能保留更多的消息
+}
+
+// StreamConsumerOptions 消费者的配置
+type StreamConsumerOptions struct {
+	ClaimMinIdle  time.Duration // 其他消费者的消息超过这个时间没有确认时可以被认领,0时使用 DefaultStreamClaimMinIdle
+	ClaimCount    int64         // 每次检查的待确认消息数量,0时使用 DefaultStreamClaimCount
+	MaxDeliveries int64         // 投递次数达到这个值的消息在认领时进入死信stream,0时不限制
+	DeadLetter    string        // 死信stream的名称,为空时使用 {name}:dlq,集群模式下需要和stream位于同一个slot
+}
+
+// StreamConsumer 消费组中的一个消费者
+type StreamConsumer[V any] struct {
+	s     *Stream[V]
+	group string
+	name  string
+	opts  StreamConsumerOptions
+	mu    sync.Mutex
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+/**
+ * 获取Stream
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *Stream[V]
+ */
+func GetStream[V any](r *Redis, name string) *Stream[V] {
+	return GetStreamWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的Stream
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *Stream[V]
+ */
+func GetStreamWithCodec[V any](r *Redis, name string, c codec.Codec) *Stream[V] {
+	return &Stream[V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 添加消息
+ *
+ * param: V value
+ * return: string 消息id
+ * return: error
+ */
+func (s *Stream[V]) Add(ctx context.Context, value V) (string, error) {
+	return s.AddWithOptions(ctx, value, StreamAddOptions{})
+}
+
+/**
+ * 添加消息并按参数裁剪
+ *
+ * param: V                value
+ * param: StreamAddOptions opts
+ * return: string 消息id
+ * return: error
+ */
+func (s *Stream[V]) AddWithOptions(ctx context.Context, value V, opts StreamAddOptions) (string, error) {
+	item, err := s.encode(value)
+	if err != nil {
+		return "", err
+	}
+	args := append([]interface{}{"xadd", s.name}, trimArgs(opts.MaxLen, opts.MinID, opts.Approx)...)
+	id := opts.ID
+	if id == "" {
+		id = "*"
+	}
+	args = append(args, id, streamValueField, item)
+	cmd := gredis.NewStringCmd(ctx, args...)
+	_ = s.r.Process(ctx, cmd)
+	return cmd.Result()
+}
+
+/**
+ * 读取id区间内的消息,包含start和end
+ *
+ * param: string start 为空时从第一条开始
+ * param: string end   为空时到最后一条
+ * param: int64  count 为0时不限制
+ * return: []StreamMessage[V]
+ * return: error
+ */
+func (s *Stream[V]) Range(ctx context.Context, start, end string, count int64) ([]StreamMessage[V], error) {
+	start, end = rangeBound(start, "-"), rangeBound(end, "+")
+	if count > 0 {
+		return s.decodeMessages(s.r.XRangeN(ctx, s.name, start, end, count).Result())
+	}
+	return s.decodeMessages(s.r.XRange(ctx, s.name, start, end).Result())
+}
+
+/**
+ * 从后往前读取id区间内的消息,包含start和end
+ *
+ * param: string end   为空时从最后一条开始
+ * param: string start 为空时到第一条
+ * param: int64  count 为0时不限制
+ * return: []StreamMessage[V]
+ * return: error
+ */
+func (s *Stream[V]) RangeReversed(ctx context.Context, end, start string, count int64) ([]StreamMessage[V], error) {
+	start, end = rangeBound(start, "-"), rangeBound(end, "+")
+	if count > 0 {
+		return s.decodeMessages(s.r.XRevRangeN(ctx, s.name, end, start, count).Result())
+	}
+	return s.decodeMessages(s.r.XRevRange(ctx, s.name, end, start).Result())
+}
+
+/**
+ * 删除消息
+ *
+ * param: ...string ids
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *Stream[V]) Remove(ctx context.Context, ids ...string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return s.r.XDel(ctx, s.name, ids...).Result()
+}
+
+/**
+ * 消息数量
+ *
+ * return: int64
+ * return: error
+ */
+func (s *Stream[V]) Size(ctx context.Context) (int64, error) {
+	return s.r.XLen(ctx, s.name).Result()
+}
+
+/**
+ * 只保留最新的maxLen条消息
+ *
+ * param: int64 maxLen
+ * param: bool  approx 是否近似裁剪
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *Stream[V]) Trim(ctx context.Context, maxLen int64, approx bool) (int64, error) {
+	if approx {
+		return s.r.XTrimApprox(ctx, s.name, maxLen).Result()
+	}
+	return s.r.XTrim(ctx, s.name, maxLen).Result()
+}
+
+/**
+ * 删除id小于minID的消息,需要redis 6.2
+ *
+ * param: string minID
+ * param: bool   approx 是否近似裁剪
+ * return: int64 删除的数量
+ * return: error
+ */
+func (s *Stream[V]) TrimMinID(ctx context.Context, minID string, approx bool) (int64, error) {
+	cmd := gredis.NewIntCmd(ctx, append([]interface{}{"xtrim", s.name}, trimArgs(0, minID, approx)...)...)
+	_ = s.r.Process(ctx, cmd)
+	return cmd.Result()
+}
+
+/**
+ * 创建消费组,stream不存在时自动创建
+ *
+ * param: string group
+ * param: string start 从这个id之后开始消费,为空时只消费创建后添加的消息,0表示从头开始
+ * return: bool 消费组已经存在时返回false
+ * return: error
+ */
+func (s *Stream[V]) CreateGroup(ctx context.Context, group, start string) (bool, error) {
+	err := s.r.XGroupCreateMkStream(ctx, s.name, group, rangeBound(start, "$")).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+/**
+ * 删除消费组
+ *
+ * param: string group
+ * return: bool 消费组是否存在
+ * return: error
+ */
+func (s *Stream[V]) RemoveGroup(ctx context.Context, group string) (bool, error) {
+	n, err := s.r.XGroupDestroy(ctx, s.name, group).Result()
+	return n > 0, err
+}
+
+/**
+ * 获取消费组中的消费者,消费组需要先通过CreateGroup创建
+ *
+ * param: string                group
+ * param: string                name 消费者名称,同一个消费组中需要唯一
+ * param: StreamConsumerOptions opts
+ * return: *StreamConsumer[V]
+ */
+func (s *Stream[V]) Consumer(group, name string, opts StreamConsumerOptions) *StreamConsumer[V] {
+	if opts.ClaimMinIdle <= 0 {
+		opts.ClaimMinIdle = DefaultStreamClaimMinIdle
+	}
+	if opts.ClaimCount <= 0 {
+		opts.ClaimCount = DefaultStreamClaimCount
+	}
+	if opts.DeadLetter == "" {
+		opts.DeadLetter = suffixName(s.name, "dlq")
+	} else {
+		opts.DeadLetter = s.r.namespaced(opts.DeadLetter)
+	}
+	return &StreamConsumer[V]{s: s, group: group, name: name, opts: opts}
+}
+
+/**
+ * 死信stream,保存投递次数达到上限的消息
+ *
+ * return: *Stream[V]
+ */
+func (c *StreamConsumer[V]) DeadLetter() *Stream[V] {
+	return &Stream[V]{object: object{r: c.s.r, name: c.opts.DeadLetter, codec: c.s.codec}}
+}
+
+/**
+ * 读取新消息,读取后需要通过Ack确认
+ *
+ * param: int64         count 为0时不限制
+ * param: time.Duration block 没有新消息时最多等待的时间,小于等于0时不等待
+ * return: []StreamMessage[V]
+ * return: error
+ */
+func (c *StreamConsumer[V]) Read(ctx context.Context, count int64, block time.Duration) ([]StreamMessage[V], error) {
+	return c.read(ctx, ">", count, block)
+}
+
+/**
+ * 读取已经投递给当前消费者但还没有确认的消息,用于消费者重启后恢复,重新读取也会增加投递次数
+ * 确认前已经被删除的消息直接确认,不会返回
+ * param: int64 count 为0时不限制
+ * return: []StreamMessage[V]
+ * return: error
+ */
+func (c *StreamConsumer[V]) ReadPending(ctx context.Context, count int64) ([]StreamMessage[V], error) {
+	return c.read(ctx, "0", count, 0)
+}
+
+/**
+ * 确认消息已经处理
+ *
+ * param: ...string ids
+ * return: int64 确认的数量
+ * return: error
+ */
+func (c *StreamConsumer[V]) Ack(ctx context.Context, ids ...string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return c.s.r.XAck(ctx, c.s.name, c.group, ids...).Result()
+}
+
+/**
+ * 认领消费组中超过ClaimMinIdle没有确认的消息,投递次数达到MaxDeliveries的消息进入死信stream
+ *
+ * return: []StreamMessage[V] 认领到的消息,需要处理后通过Ack确认
+ * return: error
+ */
+func (c *StreamConsumer[V]) ClaimStale(ctx context.Context) ([]StreamMessage[V], error) {
+	if err := c.s.r.checkSameSlot(c.s.name, c.opts.DeadLetter); err != nil {
+		return nil, err
+	}
+	pending, err := c.s.r.XPendingExt(ctx, &gredis.XPendingExtArgs{
+		Stream: c.s.name,
+		Group:  c.group,
+		Start:  "-",
+		End:    "+",
+		Count:  c.opts.ClaimCount,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	var claim []string
+	dead := []interface{}{c.group, c.opts.ClaimMinIdle.Milliseconds()}
+	for _, p := range pending {
+		if p.Idle < c.opts.ClaimMinIdle {
+			continue
+		}
+		if c.opts.MaxDeliveries > 0 && p.RetryCount >= c.opts.MaxDeliveries {
+			dead = append(dead, p.ID)
+		} else {
+			claim = append(claim, p.ID)
+		}
+	}
+	if len(dead) > 2 {
+		keys := []string{c.s.name, c.opts.DeadLetter}
+		if err = streamDeadLetterScripter.Run(ctx, c.s.r, keys, dead...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(claim) == 0 {
+		return nil, nil
+	}
+	// 认领时再次检查空闲时间,同一条消息只会被一个消费者认领
+	msgs, err := c.s.r.XClaim(ctx, &gredis.XClaimArgs{
+		Stream:   c.s.name,
+		Group:    c.group,
+		Consumer: c.name,
+		MinIdle:  c.opts.ClaimMinIdle,
+		Messages: claim,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeMessages(ctx, msgs)
+}
+
+/**
+ * 启动后台任务,定期认领超时没有确认的消息并交给handle处理,handle返回后由调用方决定是否Ack
+ * 认领出错时以err调用handle,msgs为空,下一个周期继续认领
+ *
+ * param: time.Duration interval
+ * param: func          handle
+ */
+func (c *StreamConsumer[V]) StartClaiming(interval time.Duration, handle func(ctx context.Context, msgs []StreamMessage[V], err error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	c.stop, c.done = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ctx := context.Background()
+				if msgs, err := c.ClaimStale(ctx); err != nil || len(msgs) > 0 {
+					handle(ctx, msgs, err)
+				}
+			}
+		}
+	}()
+}
+
+/**
+ * 停止后台认领任务,可以重复调用,停止后可以再次启动
+ */
+func (c *StreamConsumer[V]) Close() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+/**
+ * 从消费组中删除当前消费者,还没有确认的消息也会被删除
+ *
+ * return: int64 删除的待确认消息数量
+ * return: error
+ */
+func (c *StreamConsumer[V]) Remove(ctx context.Context) (int64, error) {
+	return c.s.r.XGroupDelConsumer(ctx, c.s.name, c.group, c.name).Result()
+}
+
+func (c *StreamConsumer[V]) read(ctx context.Context, id string, count int64, block time.Duration) ([]StreamMessage[V], error) {
+	args := &gredis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.name,
+		Streams:  []string{c.s.name, id},
+		Count:    count,
+		Block:    -1,
+	}
+	var client gredis.UniversalClient = c.s.r
+	if block > 0 {
+		args.Block = block
+		client = c.s.r.blockingClient()
+	}
+	res, err := client.XReadGroup(ctx, args).Result()
+	if err == gredis.Nil {
+		return nil, nil
+	}
+	if err != nil || len(res) == 0 {
+		return nil, err
+	}
+	return c.decodeMessages(ctx, res[0].Messages)
+}
+
+/**
+ * 解码消息,确认前已经被XDEL删除的消息没有字段,直接确认后从待确认列表中移除
+ *
+ * param: []gredis.XMessage msgs
+ * return: []StreamMessage[V]
+ * return: error
+ */
+func (c *StreamConsumer[V]) decodeMessages(ctx context.Context, msgs []gredis.XMessage) ([]StreamMessage[V], error) {
+	res, err := c.s.decodeMessages(msgs, nil)
+	if err != nil {
+		return nil, err
+	}
+	if deleted := deletedIDs(msgs); len(deleted) > 0 {
+		if _, err = c.Ack(ctx, deleted...); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (s *Stream[V]) decodeMessages(msgs []gredis.XMessage, err error) ([]StreamMessage[V], error) {
+	if err != nil {
+		return nil, err
+	}
+	res := make([]StreamMessage[V], 0, len(msgs))
+	for _, msg := range msgs {
+		// 确认前已经被XDEL删除的消息没有字段
+		data, ok := msg.Values[streamValueField].(string)
+		if !ok {
+			continue
+		}
+		v, err := decodeAs[V](s.codec, data)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, StreamMessage[V]{ID: msg.ID, Value: v})
+	}
+	return res, nil
+}
+
+func deletedIDs(msgs []gredis.XMessage) []string {
+	var ids []string
+	for _, msg := range msgs {
+		if _, ok := msg.Values[streamValueField].(string); !ok {
+			ids = append(ids, msg.ID)
+		}
+	}
+	return ids
+}
+
+func trimArgs(maxLen int64, minID string, approx bool) []interface{} {
+	var args []interface{}
+	switch {
+	case minID != "":
+		args = append(args, "minid")
+	case maxLen > 0:
+		args = append(args, "maxlen")
+	default:
+		return nil
+	}
+	if approx {
+		args = append(args, "~")
+	}
+	if minID != "" {
+		return append(args, minID)
+	}
+	return append(args, maxLen)
+}
+
+func rangeBound(id, def string) string {
+	if id == "" {
+		return def
+	}
+	return id
+}
@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"reflect"
+	"testing"
+
+	gredis "github.com/go-redis/redis/v8"
+)
+
+func TestTrimArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		maxLen int64
+		minID  string
+		approx bool
+		want   []interface{}
+	}{
+		{"none", 0, "", false, nil},
+		{"none approx", 0, "", true, nil},
+		{"maxlen", 10, "", false, []interface{}{"maxlen", int64(10)}},
+		{"maxlen approx", 10, "", true, []interface{}{"maxlen", "~", int64(10)}},
+		{"minid", 0, "1-0", false, []interface{}{"minid", "1-0"}},
+		{"minid wins", 10, "1-0", true, []interface{}{"minid", "~", "1-0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimArgs(tt.maxLen, tt.minID, tt.approx); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trimArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeBound(t *testing.T) {
+	tests := []struct {
+		id   string
+		def  string
+		want string
+	}{
+		{"", "-", "-"},
+		{"", "+", "+"},
+		{"1-0", "-", "1-0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id+tt.def, func(t *testing.T) {
+			if got := rangeBound(tt.id, tt.def); got != tt.want {
+				t.Errorf("rangeBound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeletedIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []gredis.XMessage
+		want []string
+	}{
+		{"empty", nil, nil},
+		{"none deleted", []gredis.XMessage{{ID: "1-0", Values: map[string]interface{}{streamValueField: "a"}}}, nil},
+		{"deleted", []gredis.XMessage{
+			{ID: "1-0", Values: map[string]interface{}{streamValueField: "a"}},
+			{ID: "2-0"},
+			{ID: "3-0", Values: map[string]interface{}{}},
+		}, []string{"2-0", "3-0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deletedIDs(tt.msgs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deletedIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+
+	gredis "github.com/go-redis/redis/v8"
+
+	"github.com/ainiaa/go-redisson/codec"
+)
+
+// Topic 发布订阅的channel,消息使用codec编码,同一个客户端的所有Topic共享一个订阅连接
+// 监听器在接收消息的goroutine中依次执行,耗时的处理需要自行异步执行
+type Topic[V any] struct {
+	object
+}
+
+// PatternTopic 按glob模式订阅多个channel
+type PatternTopic[V any] struct {
+	object
+}
+
+/**
+ * 获取Topic
+ *
+ * param: *Redis r
+ * param: string name
+ * return: *Topic[V]
+ */
+func GetTopic[V any](r *Redis, name string) *Topic[V] {
+	return GetTopicWithCodec[V](r, name, nil)
+}
+
+/**
+ * 获取使用指定codec的Topic
+ *
+ * param: *Redis      r
+ * param: string      name
+ * param: codec.Codec c
+ * return: *Topic[V]
+ */
+func GetTopicWithCodec[V any](r *Redis, name string, c codec.Codec) *Topic[V] {
+	return &Topic[V]{object: r.newObject(name, c)}
+}
+
+/**
+ * 获取PatternTopic
+ *
+ * param: *Redis r
+ * param: string pattern
+ * return: *PatternTopic[V]
+ */
+func GetPatternTopic[V any](r *Redis, pattern string) *PatternTopic[V] {
+	return GetPatternTopicWithCodec[V](r, pattern, nil)
+}
+
+/**
+ * 获取使用指定codec的PatternTopic
+ *
+ * param: *Redis      r
+ * param: string      pattern
+ * param: codec.Codec c
+ * return: *PatternTopic[V]
+ */
+func GetPatternTopicWithCodec[V any](r *Redis, pattern string, c codec.Codec) *PatternTopic[V] {
+	return &PatternTopic[V]{object: r.newObject(pattern, c)}
+}
+
+/**
+ * 发布消息
+ *
+ * param: V msg
+ * return: int64 收到消息的订阅者数量
+ * return: error
+ */
+func (t *Topic[V]) Publish(ctx context.Context, msg V) (int64, error) {
+	data, err := t.encode(msg)
+	if err != nil {
+		return 0, err
+	}
+	return t.r.Publish(ctx, t.name, data).Result()
+}
+
+/**
+ * 添加监听器,第一个监听器添加时订阅channel,连接断开期间的消息会丢失
+ *
+ * param: func(channel string, msg V) f 无法解码的消息会被忽略
+ * return: int64 监听器id,用于RemoveListener
+ * return: error
+ */
+func (t *Topic[V]) AddListener(ctx context.Context, f func(channel string, msg V)) (int64, error) {
+	return t.r.sharedSubscriber().add(ctx, t.name, false, topicListener(t.codec, f))
+}
+
+/**
+ * 删除监听器,最后一个监听器删除后取消订阅
+ *
+ * param: ...int64 ids
+ * return: error
+ */
+func (t *Topic[V]) RemoveListener(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return t.r.sharedSubscriber().remove(ctx, t.name, false, ids...)
+}
+
+/**
+ * 删除全部监听器并取消订阅
+ *
+ * return: error
+ */
+func (t *Topic[V]) RemoveAllListeners(ctx context.Context) error {
+	return t.r.sharedSubscriber().remove(ctx, t.name, false)
+}
+
+/**
+ * 当前客户端的监听器数量
+ *
+ * return: int
+ */
+func (t *Topic[V]) CountListeners() int {
+	return t.r.sharedSubscriber().count(t.name, false)
+}
+
+/**
+ * 所有客户端中订阅channel的连接数量
+ *
+ * return: int64
+ * return: error
+ */
+func (t *Topic[V]) CountSubscribers(ctx context.Context) (int64, error) {
+	res, err := t.r.PubSubNumSub(ctx, t.name).Result()
+	return res[t.name], err
+}
+
+/**
+ * 添加监听器,第一个监听器添加时订阅pattern,连接断开期间的消息会丢失
+ *
+ * param: func(channel string, msg V) f channel为实际收到消息的channel,无法解码的消息会被忽略
+ * return: int64 监听器id,用于RemoveListener
+ * return: error
+ */
+func (t *PatternTopic[V]) AddListener(ctx context.Context, f func(channel string, msg V)) (int64, error) {
+	return t.r.sharedSubscriber().add(ctx, t.name, true, topicListener(t.codec, f))
+}
+
+/**
+ * 删除监听器,最后一个监听器删除后取消订阅
+ *
+ * param: ...int64 ids
+ * return: error
+ */
+func (t *PatternTopic[V]) RemoveListener(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return t.r.sharedSubscriber().remove(ctx, t.name, true, ids...)
+}
+
+/**
+ * 删除全部监听器并取消订阅
+ *
+ * return: error
+ */
+func (t *PatternTopic[V]) RemoveAllListeners(ctx context.Context) error {
+	return t.r.sharedSubscriber().remove(ctx, t.name, true)
+}
+
+/**
+ * 当前客户端的监听器数量
+ *
+ * return: int
+ */
+func (t *PatternTopic[V]) CountListeners() int {
+	return t.r.sharedSubscriber().count(t.name, true)
+}
+
+func topicListener[V any](c codec.Codec, f func(channel string, msg V)) func(msg *gredis.Message) {
+	return func(msg *gredis.Message) {
+		v, err := decodeAs[V](c, msg.Payload)
+		if err != nil {
+			return
+		}
+		f(msg.Channel, v)
+	}
+}